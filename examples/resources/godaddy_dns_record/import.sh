@@ -0,0 +1,2 @@
+# Record sets are imported as domain/TYPE/name.
+terraform import godaddy_dns_record.dmarc example.com/TXT/_dmarc
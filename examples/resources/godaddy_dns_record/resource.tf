@@ -0,0 +1,51 @@
+resource "godaddy_dns_record" "dmarc" {
+  domain = "example.com"
+  type   = "TXT"
+  name   = "_dmarc"
+  ttl    = 600
+
+  values = [
+    { data = "v=DMARC1; p=reject" },
+  ]
+}
+
+resource "godaddy_dns_record" "mail" {
+  domain = "example.com"
+  type   = "MX"
+  name   = "@"
+
+  values = [
+    { mx = { priority = 1, exchange = "aspmx.l.google.com." } },
+    { mx = { priority = 5, exchange = "alt1.aspmx.l.google.com." } },
+  ]
+}
+
+resource "godaddy_dns_record" "sip" {
+  domain = "example.com"
+  type   = "SRV"
+  name   = "@"
+
+  values = [
+    {
+      srv = {
+        service  = "_sip"
+        protocol = "_tcp"
+        priority = 10
+        weight   = 60
+        port     = 5060
+        target   = "sip.example.com"
+      }
+    },
+  ]
+}
+
+resource "godaddy_dns_record" "caa" {
+  domain = "example.com"
+  type   = "CAA"
+  name   = "@"
+
+  values = [
+    { caa = { tag = "issue", value = "letsencrypt.org" } },
+    { caa = { tag = "iodef", value = "mailto:security@example.com" } },
+  ]
+}
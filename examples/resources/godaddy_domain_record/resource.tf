@@ -9,14 +9,66 @@ resource "godaddy_domain_record" "example" {
       ttl  = 3600
     },
     {
-      name     = "@"
-      type     = "MX"
-      data     = "aspmx.l.google.com."
-      ttl      = 600
-      priority = 1
+      name = "@"
+      type = "MX"
+      ttl  = 600
+      mx   = {
+        priority = 1
+        exchange = "aspmx.l.google.com."
+      }
+    },
+    {
+      name = "@"
+      type = "SRV"
+      srv  = {
+        service  = "_sip"
+        protocol = "_tls"
+        port     = 443
+        target   = "sipdir.online.lync.com."
+      }
+    },
+    {
+      name = "@"
+      type = "CAA"
+      caa  = {
+        tag   = "issue"
+        value = "letsencrypt.org"
+      }
     },
   ]
 
   addresses   = ["192.168.1.2", "192.168.1.3"]
   nameservers = ["ns7.example.com", "ns8.example.com"]
 }
+
+# Manage only the records below, leaving the rest of example.org alone.
+resource "godaddy_domain_record" "shared" {
+  domain        = "example.org"
+  authoritative = false
+
+  record = [
+    {
+      name = "_dmarc"
+      type = "TXT"
+      data = "v=DMARC1; p=reject"
+    },
+  ]
+}
+
+# Manage only the TXT and MX records at or below dev.example.net.
+resource "godaddy_domain_record" "dev" {
+  domain        = "example.net"
+  subdomain     = "dev"
+  managed_types = ["MX", "TXT"]
+
+  # Leave mail working if this resource is ever destroyed.
+  on_destroy = "retain"
+
+  record = [
+    {
+      name = "dev"
+      type = "TXT"
+      data = "v=spf1 include:_spf.google.com ~all"
+    },
+  ]
+}
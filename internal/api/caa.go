@@ -0,0 +1,141 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// CAA property tags that GoDaddy accepts
+const (
+	CAATagIssue     = "issue"
+	CAATagIssueWild = "issuewild"
+	CAATagIODef     = "iodef"
+)
+
+var caaTags = []string{CAATagIssue, CAATagIssueWild, CAATagIODef}
+
+// NewCAARecord validates and constructs a CAA record. value is given
+// unquoted, e.g. "letsencrypt.org"; the record's data is written in the
+// presentation format `flags tag "value"`.
+func NewCAARecord(name string, flags int, tag, value string, ttl int) (*DomainRecord, error) {
+	if err := ValidateCAA(flags, tag, value); err != nil {
+		return nil, err
+	}
+	return NewDomainRecord(name, CAAType, FormatCAAData(flags, tag, value), ttl)
+}
+
+// FormatCAAData renders CAA fields as record data, quoting the value.
+func FormatCAAData(flags int, tag, value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return fmt.Sprintf(`%d %s "%s"`, flags, strings.ToLower(tag), value)
+}
+
+// ParseCAAData splits CAA record data into its flags, tag and value. The
+// fields may be separated by any amount of whitespace. The value may be
+// quoted, with quotes and backslashes inside it escaped, or a single unquoted
+// word. Tags are returned in lower case.
+func ParseCAAData(data string) (flags int, tag, value string, err error) {
+	rawFlags, rest := nextCAAField(data)
+	tag, raw := nextCAAField(rest)
+	if tag == "" || raw == "" {
+		return 0, "", "", fmt.Errorf(`CAA data must be of the form: flags tag "value", got %q`, data)
+	}
+
+	flags, err = strconv.Atoi(rawFlags)
+	if err != nil {
+		return 0, "", "", fmt.Errorf("CAA flags must be a number, got %q", rawFlags)
+	}
+	tag = strings.ToLower(tag)
+
+	raw = strings.TrimSpace(raw)
+	if !strings.HasPrefix(raw, `"`) {
+		if strings.ContainsAny(raw, "\" \t") {
+			return 0, "", "", fmt.Errorf("CAA value %s must be quoted", raw)
+		}
+		return flags, tag, raw, nil
+	}
+	if len(raw) < 2 || !strings.HasSuffix(raw, `"`) {
+		return 0, "", "", fmt.Errorf("CAA value %s is missing its closing quote", raw)
+	}
+
+	var b strings.Builder
+	inner := raw[1 : len(raw)-1]
+	for i := 0; i < len(inner); i++ {
+		switch c := inner[i]; c {
+		case '\\':
+			if i+1 == len(inner) {
+				return 0, "", "", fmt.Errorf("CAA value %s ends with an unfinished escape", raw)
+			}
+			i++
+			b.WriteByte(inner[i])
+		case '"':
+			return 0, "", "", fmt.Errorf(`CAA value %s contains an unescaped quote; use \" inside the value`, raw)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return flags, tag, b.String(), nil
+}
+
+// nextCAAField splits the first whitespace-separated field off s, returning
+// it and the rest of s without its leading whitespace.
+func nextCAAField(s string) (field, rest string) {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	end := strings.IndexFunc(s, unicode.IsSpace)
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], strings.TrimLeftFunc(s[end:], unicode.IsSpace)
+}
+
+// ValidateCAA checks the fields of a CAA record. issue and issuewild values
+// name a certificate authority's domain, optionally followed by parameters,
+// or are empty to forbid issuance; iodef values are mailto: or http(s) URLs.
+func ValidateCAA(flags int, tag, value string) error {
+	if flags < 0 || flags > 255 {
+		return errors.New("CAA flags must be between 0..255")
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return errors.New("CAA value must be a single line")
+	}
+
+	switch strings.ToLower(tag) {
+	case CAATagIssue, CAATagIssueWild:
+		domain, _, _ := strings.Cut(value, ";")
+		domain = strings.TrimSpace(domain)
+		if domain != "" && strings.ContainsAny(domain, " \t/:@") {
+			return fmt.Errorf("CAA %s value must be a certificate authority's domain, e.g. letsencrypt.org, got %q", tag, value)
+		}
+	case CAATagIODef:
+		if !strings.HasPrefix(value, "mailto:") && !strings.HasPrefix(value, "https://") && !strings.HasPrefix(value, "http://") {
+			return fmt.Errorf("CAA iodef value must be a mailto:, http:// or https:// URL, got %q", value)
+		}
+	default:
+		return fmt.Errorf("CAA tag must be one of: %s, got %q", caaTags, tag)
+	}
+	return nil
+}
+
+// validateCAAData is ValidateData for CAA records.
+func validateCAAData(data string) error {
+	flags, tag, value, err := ParseCAAData(data)
+	if err != nil {
+		return err
+	}
+	return ValidateCAA(flags, tag, value)
+}
+
+// sameCAAData reports whether two CAA record datas hold the same fields,
+// however they are quoted.
+func sameCAAData(a, b string) bool {
+	fa, ta, va, err := ParseCAAData(a)
+	if err != nil {
+		return false
+	}
+	fb, tb, vb, err := ParseCAAData(b)
+	return err == nil && fa == fb && ta == tb && va == vb
+}
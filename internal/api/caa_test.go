@@ -0,0 +1,90 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCAAData(t *testing.T) {
+	var criteria = []struct {
+		Name     string
+		Data     string
+		Flags    int
+		Tag      string
+		Value    string
+		Negative bool
+	}{
+		{"Given a quoted value", `0 issue "letsencrypt.org"`, 0, CAATagIssue, "letsencrypt.org", false},
+		{"Given an unquoted value", `128 ISSUEWILD letsencrypt.org`, 128, CAATagIssueWild, "letsencrypt.org", false},
+		{"Given an empty value", `0 issue ";"`, 0, CAATagIssue, ";", false},
+		{"Given escaped quotes", `0 iodef "mailto:\"dns\"@example.com"`, 0, CAATagIODef, `mailto:"dns"@example.com`, false},
+		{"Given a value with spaces", `0 issue "ca.example.net; account=230123"`, 0, CAATagIssue, "ca.example.net; account=230123", false},
+		{"Given extra whitespace", "0  issue\t \"letsencrypt.org\" ", 0, CAATagIssue, "letsencrypt.org", false},
+		{"Given a quoted value with leading spaces", `0 issue "  letsencrypt.org"`, 0, CAATagIssue, "  letsencrypt.org", false},
+		{"Given an unquoted value with spaces", `0 issue ca.example.net; account=230123`, 0, "", "", true},
+		{"Given an unescaped quote", `0 issue "lets"encrypt.org"`, 0, "", "", true},
+		{"Given a missing closing quote", `0 issue "letsencrypt.org`, 0, "", "", true},
+		{"Given no value", `0 issue`, 0, "", "", true},
+		{"Given non-numeric flags", `critical issue "letsencrypt.org"`, 0, "", "", true},
+	}
+	for _, test := range criteria {
+		t.Run(test.Name, func(t *testing.T) {
+			flags, tag, value, err := ParseCAAData(test.Data)
+			if test.Negative {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.Flags, flags)
+			assert.Equal(t, test.Tag, tag)
+			assert.Equal(t, test.Value, value)
+
+			// Formatting the fields again gives data that parses the same.
+			reflags, retag, revalue, err := ParseCAAData(FormatCAAData(flags, tag, value))
+			assert.NoError(t, err)
+			assert.Equal(t, []any{flags, tag, value}, []any{reflags, retag, revalue})
+		})
+	}
+}
+
+func TestNewCAARecord(t *testing.T) {
+	var criteria = []struct {
+		Name     string
+		Flags    int
+		Tag      string
+		Value    string
+		Negative bool
+	}{
+		{"Given an issuer", 0, CAATagIssue, "letsencrypt.org", false},
+		{"Given an issuer with parameters", 0, CAATagIssueWild, "pki.goog; cansignhttpexchanges=yes", false},
+		{"Given an empty issuer", 0, CAATagIssue, ";", false},
+		{"Given a mailto report URL", 0, CAATagIODef, "mailto:security@example.com", false},
+		{"Given an https report URL", 128, CAATagIODef, "https://example.com/caa", false},
+		{"Given an unknown tag", 0, "contactemail", "security@example.com", true},
+		{"Given an issuer URL", 0, CAATagIssue, "https://letsencrypt.org", true},
+		{"Given a report address without a scheme", 0, CAATagIODef, "security@example.com", true},
+		{"Given flags out of range", 256, CAATagIssue, "letsencrypt.org", true},
+	}
+	for _, test := range criteria {
+		t.Run(test.Name, func(t *testing.T) {
+			rec, err := NewCAARecord("@", test.Flags, test.Tag, test.Value, DefaultTTL)
+			if test.Negative {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, CAAType, rec.Type)
+			assert.NoError(t, ValidateData(CAAType, rec.Data))
+		})
+	}
+}
+
+func TestSameRecordComparesCAAFields(t *testing.T) {
+	quoted := &DomainRecord{Type: CAAType, Name: "@", Data: `0 issue "letsencrypt.org"`, TTL: DefaultTTL}
+	unquoted := &DomainRecord{Type: CAAType, Name: "@", Data: `0 issue letsencrypt.org`, TTL: DefaultTTL}
+	other := &DomainRecord{Type: CAAType, Name: "@", Data: `0 issue "pki.goog"`, TTL: DefaultTTL}
+
+	assert.True(t, SameRecord(quoted, unquoted))
+	assert.False(t, SameRecord(quoted, other))
+}
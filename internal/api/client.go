@@ -1,17 +1,17 @@
 package api
 
 import (
-	"bytes"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net"
 	"net/http"
 	"net/url"
 	"strings"
-	"sync"
 	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 const (
@@ -19,67 +19,101 @@ const (
 	headerAuthorization = "Authorization"
 	headerContent       = "Content-Type"
 	headerCustomerID    = "X-Shopper-Id"
+	headerUserAgent     = "User-Agent"
+	headerRequestID     = "X-Request-Id"
 	mediaTypeJSON       = "application/json"
-	rateLimit           = 1 * time.Second
 )
 
 // Client is a GoDaddy API client
 type Client struct {
-	baseURL string
-	key     string
-	secret  string
-	client  *http.Client
-}
-
-// rateLimitedTransport throttles API calls to GoDaddy. It appears that
-// the rate limit is 60 requests per minute, which can be throttled and
-// enforced at a maximum of one request/second.
-type rateLimitedTransport struct {
-	delegate http.RoundTripper
-	throttle time.Time
-	sync.Mutex
-}
-
-func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	t.Lock()
-	defer t.Unlock()
-
-	if t.throttle.After(time.Now()) {
-		delta := t.throttle.Sub(time.Now())
-		time.Sleep(delta)
-	}
-
-	t.throttle = time.Now().Add(rateLimit)
-	return t.delegate.RoundTrip(req)
+	baseURL     string
+	environment string
+	key         string
+	secret      string
+	userAgent   string
+	client      *http.Client
+	retry       RetryPolicy
+	limiter     *rateLimiter
+	customers   customerIDCache
+
+	// Settings collected from ClientOpts and applied once by NewClient.
+	timeout           time.Duration
+	middleware        []func(http.RoundTripper) http.RoundTripper
+	proxy             *url.URL
+	tlsConfig         *tls.Config
+	requestsPerMinute int
+	adaptiveRateLimit bool
 }
 
 // NewClient constructs a new GoDaddy API client or an error if the supplied
 // input is invalid.
-func NewClient(baseURL, key, secret string) (*Client, error) {
+func NewClient(baseURL, key, secret string, opts ...ClientOpt) (*Client, error) {
 	baseURL, err := formatURL(baseURL)
 	if err != nil {
 		return nil, err
 	}
 
-	var netTransport = &http.Transport{
-		Dial: (&net.Dialer{
-			Timeout: 10 * time.Second,
-		}).Dial,
-		TLSHandshakeTimeout: 10 * time.Second,
+	c := &Client{
+		baseURL:     baseURL,
+		environment: EnvironmentForBaseURL(baseURL),
+		key:         strings.TrimSpace(key),
+		secret:      strings.TrimSpace(secret),
+		userAgent:   defaultUserAgent,
+		retry:       DefaultRetryPolicy,
+
+		requestsPerMinute: DefaultRequestsPerMinute,
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
 	}
 
-	return &Client{
-		baseURL: baseURL,
-		key:     strings.TrimSpace(key),
-		secret:  strings.TrimSpace(secret),
-		client: &http.Client{
-			Timeout: time.Second * 30,
-			Transport: &rateLimitedTransport{
-				delegate: netTransport,
-				throttle: time.Now().Add(-(rateLimit)),
+	var httpClient http.Client
+	if c.client != nil {
+		httpClient = *c.client
+	} else {
+		httpClient = http.Client{
+			Timeout: defaultTimeout,
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				DialContext: (&net.Dialer{
+					Timeout: 10 * time.Second,
+				}).DialContext,
+				TLSHandshakeTimeout: 10 * time.Second,
 			},
-		},
-	}, nil
+		}
+	}
+	if c.timeout > 0 {
+		httpClient.Timeout = c.timeout
+	}
+
+	transport := httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if c.proxy != nil || c.tlsConfig != nil {
+		base, ok := transport.(*http.Transport)
+		if !ok {
+			return nil, fmt.Errorf("proxy and TLS options require an *http.Transport, got %T", transport)
+		}
+		base = base.Clone()
+		if c.proxy != nil {
+			base.Proxy = http.ProxyURL(c.proxy)
+		}
+		if c.tlsConfig != nil {
+			base.TLSClientConfig = c.tlsConfig
+		}
+		transport = base
+	}
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		transport = c.middleware[i](transport)
+	}
+	httpClient.Transport = transport
+
+	c.client = &httpClient
+	c.limiter = sharedRateLimiter(c.key, c.requestsPerMinute, c.adaptiveRateLimit)
+	return c, nil
 }
 
 func (c *Client) execute(customerID string, req *http.Request, result interface{}) error {
@@ -87,80 +121,111 @@ func (c *Client) execute(customerID string, req *http.Request, result interface{
 		req.Header.Set(headerCustomerID, customerID)
 	}
 
+	requestID := newRequestID()
 	req.Header.Set(headerAccept, mediaTypeJSON)
+	req.Header.Set(headerUserAgent, c.userAgent)
 	req.Header.Set(headerContent, mediaTypeJSON)
+	req.Header.Set(headerRequestID, requestID)
 	req.Header.Set(headerAuthorization, fmt.Sprintf("sso-key %s:%s", c.key, c.secret))
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return err
+	ctx := c.logContext(req.Context())
+	ctx = tflog.SubsystemSetField(ctx, logSubsystem, "method", req.Method)
+	ctx = tflog.SubsystemSetField(ctx, logSubsystem, "url", req.URL.String())
+	ctx = tflog.SubsystemSetField(ctx, logSubsystem, "request_id", requestID)
+	if c.environment != "" {
+		ctx = tflog.SubsystemSetField(ctx, logSubsystem, "environment", c.environment)
 	}
+	logRequestBody(ctx, req)
 
-	defer resp.Body.Close()
+	for attempt := 0; ; attempt++ {
+		attemptReq, err := rewind(req, attempt)
+		if err != nil {
+			return err
+		}
 
-	if err = validate(resp); err != nil {
-		return err
-	}
+		if err := c.limiter.wait(req.Context()); err != nil {
+			return err
+		}
 
-	buffer := new(bytes.Buffer)
-	body, err := io.ReadAll(io.TeeReader(resp.Body, buffer))
-	log.Printf("%s %s", resp.Status, buffer)
-	if err != nil {
-		return err
-	}
+		tflog.SubsystemDebug(ctx, logSubsystem, "sending API request", map[string]interface{}{"attempt": attempt + 1})
+		start := time.Now()
+		resp, err := c.client.Do(attemptReq)
+		if err != nil {
+			if !c.retry.retryable(req, 0, attempt) || req.Context().Err() != nil {
+				return err
+			}
+			tflog.SubsystemWarn(ctx, logSubsystem, "retrying API request after error", map[string]interface{}{
+				"error":       err.Error(),
+				"attempt":     attempt + 1,
+				"max_retries": c.retry.MaxRetries,
+			})
+			if err := c.retry.wait(req.Context(), attempt, 0); err != nil {
+				return err
+			}
+			continue
+		}
 
-	if result == nil {
-		return nil
-	} else if err = json.Unmarshal(body, result); err != nil {
-		return err
-	}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		logResponse(ctx, resp, body, time.Since(start))
+		if err != nil {
+			return err
+		}
 
-	return nil
-}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			c.limiter.throttled(retryAfter(resp, body))
+		} else {
+			c.limiter.succeeded()
+		}
 
-func validate(resp *http.Response) error {
-	if resp.StatusCode < http.StatusBadRequest {
-		return nil
-	}
+		if err = validate(resp, body); err != nil {
+			if !c.retry.retryable(req, resp.StatusCode, attempt) {
+				return err
+			}
+			tflog.SubsystemWarn(ctx, logSubsystem, "retrying API request after error response", map[string]interface{}{
+				"status":      resp.StatusCode,
+				"attempt":     attempt + 1,
+				"max_retries": c.retry.MaxRetries,
+			})
+			if err := c.retry.wait(req.Context(), attempt, retryAfter(resp, body)); err != nil {
+				return err
+			}
+			continue
+		}
 
-	buffer := new(bytes.Buffer)
-	body, err := io.ReadAll(io.TeeReader(resp.Body, buffer))
-	log.Println(buffer)
-	if err != nil {
-		return err
+		if result == nil {
+			return nil
+		}
+		return json.Unmarshal(body, result)
 	}
+}
 
-	var errResp = struct {
-		Code    string `json:"code"`
-		Message string `json:"message"`
-		Fields  []struct {
-			Code        string `json:"code"`
-			Message     string `json:"message"`
-			Path        string `json:"path"`
-			PathRelated string `json:"pathRelated"`
-		} `json:"fields"`
-	}{}
-
-	if err := json.Unmarshal(body, &errResp); err != nil {
-		return err
+// rewind returns the request to send for the given attempt. Retries need a
+// fresh copy of the body, which http.NewRequest makes available via GetBody
+// for the in-memory readers used throughout this package.
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.GetBody == nil {
+		return req, nil
 	}
-
-	if len(errResp.Fields) == 0 {
-		return fmt.Errorf("[%d:%s] %s", resp.StatusCode, errResp.Code, errResp.Message)
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
 	}
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone, nil
+}
 
-	var b bytes.Buffer
-	b.WriteString(fmt.Sprintf("[%d:%s] %s (", resp.StatusCode, errResp.Code, errResp.Message))
-	for i, field := range errResp.Fields {
-		if i > 0 {
-			b.WriteString(", ")
-		}
-		b.WriteString(fmt.Sprintf("%s [%s]: %s", field.Path, field.Code, field.Message))
+func validate(resp *http.Response, body []byte) error {
+	if resp.StatusCode < http.StatusBadRequest {
+		return nil
 	}
-	b.WriteString(")")
-	return fmt.Errorf("%s", b.String())
+	return newError(resp, body)
 }
 
+// formatURL validates a base URL and normalizes it to scheme://host[/path]
+// without a trailing slash. A path is kept so that the API can be reached
+// through a gateway that routes on a path prefix.
 func formatURL(base string) (string, error) {
 	baseURL, err := url.Parse(base)
 	if err != nil {
@@ -168,8 +233,8 @@ func formatURL(base string) (string, error) {
 	}
 
 	if baseURL.Host == "" || baseURL.Scheme == "" {
-		return "", fmt.Errorf("invalid baseUrl. expected format: scheme://host")
+		return "", fmt.Errorf("invalid baseUrl. expected format: scheme://host[/path]")
 	}
 
-	return fmt.Sprintf("%s://%s", baseURL.Scheme, baseURL.Host), err
+	return strings.TrimSuffix(fmt.Sprintf("%s://%s%s", baseURL.Scheme, baseURL.Host, baseURL.EscapedPath()), "/"), nil
 }
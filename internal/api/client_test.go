@@ -0,0 +1,209 @@
+package api
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	retryBaseDelay = time.Millisecond
+}
+
+// newTestClient returns a client pointed at an httptest server, bypassing the
+// rate limiter so that tests run quickly.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return &Client{
+		baseURL: srv.URL,
+		key:     "key",
+		secret:  "secret",
+		client:  srv.Client(),
+		retry:   RetryPolicy{MaxRetries: 3, MaxBackoff: 10 * time.Millisecond},
+	}
+}
+
+func TestExecuteRetriesThrottledRequests(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"code":"TOO_MANY_REQUESTS","message":"slow down","retryAfterSec":0}`))
+			return
+		}
+		w.Write([]byte(`{"domainId":1,"domain":"example.com"}`))
+	})
+
+	d, err := client.GetDomain(context.Background(), "", "example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), d.ID)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestExecuteGivesUpAfterMaxRetries(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"code":"UNAVAILABLE","message":"try later"}`))
+	})
+
+	_, err := client.GetDomain(context.Background(), "", "example.com")
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+}
+
+func TestExecuteDoesNotRetryPurchaseOnServerError(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"code":"INTERNAL_SERVER_ERROR","message":"oops"}`))
+	})
+
+	_, err := client.PurchaseDomain(context.Background(), "", &DomainPurchase{Domain: "example.com"})
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestExecuteResendsBodyOnRetry(t *testing.T) {
+	var bodies []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(buf))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"code":"TOO_MANY_REQUESTS","message":"slow down"}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	})
+
+	_, err := client.PurchaseDomain(context.Background(), "", &DomainPurchase{Domain: "example.com"})
+	assert.Nil(t, err)
+	assert.Len(t, bodies, 2)
+	assert.Equal(t, bodies[0], bodies[1])
+	assert.NotEmpty(t, bodies[1])
+}
+
+func TestRetryAfter(t *testing.T) {
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"7"}}}
+	assert.Equal(t, 7*time.Second, retryAfter(resp, nil))
+
+	resp = &http.Response{Header: http.Header{}}
+	assert.Equal(t, 30*time.Second, retryAfter(resp, []byte(`{"retryAfterSec":30}`)))
+	assert.Equal(t, time.Duration(0), retryAfter(resp, []byte(`<html>`)))
+}
+
+func TestNewClientOptions(t *testing.T) {
+	var userAgent, seen string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		seen = r.Header.Get("X-Middleware")
+		w.Write([]byte(`{"domainId":1}`))
+	}))
+	defer srv.Close()
+
+	tag := func(value string) func(http.RoundTripper) http.RoundTripper {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				req.Header.Add("X-Middleware", value)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+
+	client, err := NewClient(srv.URL, "options-key", "secret",
+		WithHTTPClient(srv.Client()),
+		WithTimeout(5*time.Second),
+		WithUserAgent("test-agent/1.0"),
+		WithTransportMiddleware(tag("outer")),
+		WithTransportMiddleware(tag("inner")),
+		WithRateLimit(6000, false),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, 5*time.Second, client.client.Timeout)
+	assert.Equal(t, time.Duration(0), srv.Client().Timeout)
+
+	_, err = client.GetDomain(context.Background(), "", "example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "test-agent/1.0", userAgent)
+	assert.Equal(t, "outer", seen)
+
+	_, err = NewClient(srv.URL, "options-key", "secret", WithTimeout(0))
+	assert.NotNil(t, err)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestBaseURLPathIsPreserved(t *testing.T) {
+	var requested string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		w.Write([]byte(`{"domainId":1}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL+"/godaddy/", "path-key", "secret", WithRateLimit(6000, false))
+	assert.Nil(t, err)
+	assert.Equal(t, srv.URL+"/godaddy", client.baseURL)
+
+	_, err = client.GetDomain(context.Background(), "", "example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "/godaddy/v1/domains/example.com", requested)
+}
+
+func TestProxyAndCACertificates(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"domainId":1}`))
+	}))
+	defer srv.Close()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	var proxied bool
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.Method == http.MethodConnect
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer proxy.Close()
+
+	client, err := NewClient(srv.URL, "tls-key", "secret", WithCACertificates(caPEM), WithRateLimit(6000, false))
+	assert.Nil(t, err)
+	_, err = client.GetDomain(context.Background(), "", "example.com")
+	assert.Nil(t, err)
+
+	client, err = NewClient(srv.URL, "tls-key", "secret", WithProxyURL(proxy.URL), WithRetryPolicy(RetryPolicy{}), WithRateLimit(6000, false))
+	assert.Nil(t, err)
+	_, err = client.GetDomain(context.Background(), "", "example.com")
+	assert.NotNil(t, err)
+	assert.True(t, proxied)
+
+	_, err = NewClient(srv.URL, "tls-key", "secret", WithCACertificates([]byte("not a certificate")))
+	assert.NotNil(t, err)
+}
+
+func TestVerifyCredentials(t *testing.T) {
+	var query string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"code":"ACCESS_DENIED","message":"Authenticated user is not allowed access"}`))
+	})
+
+	err := client.VerifyCredentials(context.Background())
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.Equal(t, "limit=1", query)
+}
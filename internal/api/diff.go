@@ -0,0 +1,327 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// RecordFilter reports whether a record is managed by the caller. Records it
+// rejects are never changed by DiffRecords; a nil filter manages everything.
+type RecordFilter func(*DomainRecord) bool
+
+// RecordChangeAction is the kind of write a RecordChange needs
+type RecordChangeAction string
+
+const (
+	// ReplaceType replaces every record of a type
+	ReplaceType RecordChangeAction = "replace"
+	// ReplaceName replaces the records of a type with a single name
+	ReplaceName RecordChangeAction = "replace_name"
+	// DeleteName deletes the records of a type with a single name
+	DeleteName RecordChangeAction = "delete_name"
+)
+
+// RecordChange is a single write needed to bring a zone in line with the
+// desired records. Name is empty for ReplaceType. Before holds the records
+// the write overwrites and After the records it leaves in their place.
+type RecordChange struct {
+	Action RecordChangeAction
+	Type   string
+	Name   string
+	Before []*DomainRecord
+	After  []*DomainRecord
+}
+
+func (c RecordChange) String() string {
+	switch c.Action {
+	case ReplaceName:
+		return fmt.Sprintf("replace %s %s (%d -> %d records)", c.Type, c.Name, len(c.Before), len(c.After))
+	case DeleteName:
+		return fmt.Sprintf("delete %s %s (%d records)", c.Type, c.Name, len(c.Before))
+	}
+	return fmt.Sprintf("replace %s (%d -> %d records)", c.Type, len(c.Before), len(c.After))
+}
+
+// inverse returns the change that restores the records c overwrites.
+func (c RecordChange) inverse() RecordChange {
+	inv := RecordChange{Action: c.Action, Type: c.Type, Name: c.Name, Before: c.After, After: c.Before}
+	switch {
+	case c.Action == DeleteName:
+		inv.Action = ReplaceName
+	case c.Action == ReplaceName && len(c.Before) == 0:
+		inv.Action = DeleteName
+	}
+	return inv
+}
+
+// scope describes the records c writes, e.g. "TXT" or "TXT _dmarc".
+func (c RecordChange) scope() string {
+	if c.Name == "" {
+		return c.Type
+	}
+	return c.Type + " " + c.Name
+}
+
+// RecordChangeSet is the ordered list of writes produced by DiffRecords
+type RecordChangeSet []RecordChange
+
+// Empty reports whether the zone already matches
+func (cs RecordChangeSet) Empty() bool {
+	return len(cs) == 0
+}
+
+// Removed returns the current records that applying the change set deletes
+// or replaces.
+func (cs RecordChangeSet) Removed() []*DomainRecord {
+	removed := make([]*DomainRecord, 0)
+	for _, c := range cs {
+		for _, before := range c.Before {
+			if !containsRecord(c.After, before) {
+				removed = append(removed, before)
+			}
+		}
+	}
+	return removed
+}
+
+func (cs RecordChangeSet) String() string {
+	if cs.Empty() {
+		return "no changes"
+	}
+	parts := make([]string, 0, len(cs))
+	for _, c := range cs {
+		parts = append(parts, c.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// DiffRecords compares the records currently on a domain with the desired
+// ones and returns the smallest set of writes that reconciles them. Current
+// records that managed rejects are carried over unchanged.
+//
+// Types are compared name by name. When a single name differs the change is
+// scoped to that name; when several do, the whole type is replaced in one
+// request. SOA records are never written, and NS records are left alone
+// unless some are desired, as GoDaddy rejects empty NS updates.
+func DiffRecords(current, desired []*DomainRecord, managed RecordFilter) RecordChangeSet {
+	changes := RecordChangeSet{}
+	for _, t := range supportedTypes {
+		want := recordsOfType(t, desired)
+		if IsDisallowed(t, want) {
+			continue
+		}
+
+		have := recordsOfType(t, current)
+		for _, rec := range have {
+			if managed != nil && !managed(rec) {
+				want = append(want, rec)
+			}
+		}
+
+		haveByName, wantByName := recordsByName(have), recordsByName(want)
+		var changed []string
+		for name := range unionKeys(haveByName, wantByName) {
+			if !sameRecords(haveByName[name], wantByName[name]) {
+				changed = append(changed, name)
+			}
+		}
+		sort.Strings(changed)
+
+		switch {
+		case len(changed) == 0:
+			continue
+		case len(changed) == 1:
+			name := changed[0]
+			change := RecordChange{Action: ReplaceName, Type: t, Name: name, Before: haveByName[name], After: wantByName[name]}
+			if len(change.After) == 0 {
+				change.Action = DeleteName
+				change.Name = change.Before[0].Name
+			} else {
+				change.Name = change.After[0].Name
+			}
+			changes = append(changes, change)
+		default:
+			changes = append(changes, RecordChange{Action: ReplaceType, Type: t, Before: have, After: want})
+		}
+	}
+	return changes
+}
+
+// ApplyRecordChanges makes the writes in changes, in order, stopping at the
+// first failure. The failure is returned as a *RecordWriteError.
+func (c *Client) ApplyRecordChanges(ctx context.Context, customerID, domain string, changes RecordChangeSet) error {
+	for _, change := range changes {
+		if err := c.applyRecordChange(ctx, customerID, domain, change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncDomainRecords reads the domain's records, works out what differs from
+// desired and writes only that. The change set is returned even when a
+// write fails, so that callers can report what was attempted.
+//
+// The records read first serve as a snapshot: if a write fails, the writes
+// already made are reverted so the zone isn't left half updated, and a
+// *RecordRollbackError reports how that went.
+func (c *Client) SyncDomainRecords(ctx context.Context, customerID, domain string, desired []*DomainRecord, managed RecordFilter) (RecordChangeSet, error) {
+	return c.SyncDomainRecordsFunc(ctx, customerID, domain, func(current []*DomainRecord) RecordChangeSet {
+		return DiffRecords(current, desired, managed)
+	})
+}
+
+// SyncDomainRecordsFunc is SyncDomainRecords for callers that need the
+// current records to work out the changes, such as when folding several
+// updates into one.
+func (c *Client) SyncDomainRecordsFunc(ctx context.Context, customerID, domain string, diff func(current []*DomainRecord) RecordChangeSet) (RecordChangeSet, error) {
+	current, err := c.GetDomainRecords(ctx, customerID, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := diff(current)
+	for i, change := range changes {
+		err := c.applyRecordChange(ctx, customerID, domain, change)
+		if err == nil {
+			continue
+		}
+
+		// A request GoDaddy rejected changed nothing; anything else, such as a
+		// timeout, may have been applied and is reverted too.
+		applied := changes[:i]
+		var apiErr *Error
+		if !errors.As(err, &apiErr) || apiErr.StatusCode >= http.StatusInternalServerError {
+			applied = changes[:i+1]
+		}
+		if len(applied) == 0 {
+			return changes, err
+		}
+		return changes, c.rollbackRecordChanges(ctx, customerID, domain, applied, err)
+	}
+	return changes, nil
+}
+
+// rollbackRecordChanges reverts applied, newest first, restoring the records
+// each change overwrote. It keeps going past failures so that as much of the
+// zone as possible is restored.
+func (c *Client) rollbackRecordChanges(ctx context.Context, customerID, domain string, applied RecordChangeSet, cause error) error {
+	// The rollback must run even if the apply was cancelled.
+	ctx = context.WithoutCancel(ctx)
+
+	rbErr := &RecordRollbackError{Err: cause}
+	for i := len(applied) - 1; i >= 0; i-- {
+		change := applied[i]
+		if err := c.applyRecordChange(ctx, customerID, domain, change.inverse()); err != nil {
+			rbErr.LeftChanged = append(rbErr.LeftChanged, change.scope())
+			if rbErr.RollbackErr == nil {
+				rbErr.RollbackErr = err
+			}
+			continue
+		}
+		rbErr.RolledBack = append(rbErr.RolledBack, change.scope())
+	}
+	return rbErr
+}
+
+func (c *Client) applyRecordChange(ctx context.Context, customerID, domain string, change RecordChange) error {
+	var err error
+	switch change.Action {
+	case ReplaceName:
+		err = c.ReplaceDomainRecordsByTypeAndName(ctx, customerID, domain, change.Type, change.Name, change.After)
+	case DeleteName:
+		err = c.DeleteDomainRecordsByTypeAndName(ctx, customerID, domain, change.Type, change.Name)
+	default:
+		err = c.ReplaceDomainRecordsByType(ctx, customerID, domain, change.Type, change.After)
+	}
+	if err != nil {
+		return &RecordWriteError{Type: change.Type, Name: change.Name, Records: change.After, Err: err}
+	}
+	return nil
+}
+
+// SameRecord reports whether two records are equivalent, treating names and
+// types case-insensitively, an unset port as zero, and CAA data the same
+// however its value is quoted.
+func SameRecord(a, b *DomainRecord) bool {
+	return strings.EqualFold(a.Type, b.Type) &&
+		strings.EqualFold(a.Name, b.Name) &&
+		(a.Data == b.Data || strings.EqualFold(a.Type, CAAType) && sameCAAData(a.Data, b.Data)) &&
+		a.TTL == b.TTL &&
+		a.Priority == b.Priority &&
+		a.Weight == b.Weight &&
+		a.Service == b.Service &&
+		a.Protocol == b.Protocol &&
+		portOf(a) == portOf(b)
+}
+
+func portOf(r *DomainRecord) int {
+	if r.Port == nil {
+		return 0
+	}
+	return *r.Port
+}
+
+func containsRecord(records []*DomainRecord, r *DomainRecord) bool {
+	for _, candidate := range records {
+		if SameRecord(candidate, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameRecords compares two record lists as multisets.
+func sameRecords(a, b []*DomainRecord) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	used := make([]bool, len(b))
+	for _, ra := range a {
+		found := false
+		for i, rb := range b {
+			if !used[i] && SameRecord(ra, rb) {
+				used[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func recordsOfType(t string, records []*DomainRecord) []*DomainRecord {
+	typeRecords := make([]*DomainRecord, 0)
+	for _, record := range records {
+		if strings.EqualFold(record.Type, t) {
+			typeRecords = append(typeRecords, record)
+		}
+	}
+	return typeRecords
+}
+
+func recordsByName(records []*DomainRecord) map[string][]*DomainRecord {
+	byName := map[string][]*DomainRecord{}
+	for _, r := range records {
+		name := strings.ToLower(r.Name)
+		byName[name] = append(byName[name], r)
+	}
+	return byName
+}
+
+func unionKeys(a, b map[string][]*DomainRecord) map[string]struct{} {
+	keys := map[string]struct{}{}
+	for k := range a {
+		keys[k] = struct{}{}
+	}
+	for k := range b {
+		keys[k] = struct{}{}
+	}
+	return keys
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func record(t, name, data string) *DomainRecord {
+	return &DomainRecord{Type: t, Name: name, Data: data, TTL: DefaultTTL}
+}
+
+func TestDiffRecords(t *testing.T) {
+	current := []*DomainRecord{
+		record(AType, "@", "192.0.2.1"),
+		record(CNameType, "www", "@"),
+		record(TXTType, "@", "v=spf1 -all"),
+		record(TXTType, "_dmarc", "v=DMARC1; p=none"),
+		record(NSType, "@", "ns1.domaincontrol.com"),
+		record(SOAType, "@", "ns1.domaincontrol.com"),
+	}
+
+	var criteria = []struct {
+		Name     string
+		Desired  []*DomainRecord
+		Managed  RecordFilter
+		Expected []string
+	}{
+		{"Given no changes", []*DomainRecord{
+			record(AType, "@", "192.0.2.1"), record(CNameType, "www", "@"),
+			record(TXTType, "_dmarc", "v=DMARC1; p=none"), record(TXTType, "@", "v=spf1 -all"),
+		}, nil, []string{}},
+		{"Given one changed name", []*DomainRecord{
+			record(AType, "@", "192.0.2.1"), record(CNameType, "www", "@"),
+			record(TXTType, "@", "v=spf1 -all"), record(TXTType, "_dmarc", "v=DMARC1; p=reject"),
+		}, nil, []string{"replace TXT _dmarc (1 -> 1 records)"}},
+		{"Given a removed name", []*DomainRecord{
+			record(AType, "@", "192.0.2.1"), record(CNameType, "www", "@"), record(TXTType, "@", "v=spf1 -all"),
+		}, nil, []string{"delete TXT _dmarc (1 records)"}},
+		{"Given several changed names", []*DomainRecord{
+			record(AType, "@", "192.0.2.1"), record(CNameType, "www", "@"),
+			record(TXTType, "@", "v=spf1 mx -all"), record(TXTType, "_dmarc", "v=DMARC1; p=reject"),
+		}, nil, []string{"replace TXT (2 -> 2 records)"}},
+		{"Given a new type", []*DomainRecord{
+			record(AType, "@", "192.0.2.1"), record(CNameType, "www", "@"),
+			record(TXTType, "@", "v=spf1 -all"), record(TXTType, "_dmarc", "v=DMARC1; p=none"),
+			record(MXType, "@", "mx.example.com"),
+		}, nil, []string{"replace MX @ (0 -> 1 records)"}},
+		{"Given unmanaged records", []*DomainRecord{
+			record(AType, "@", "192.0.2.2"),
+		}, func(r *DomainRecord) bool { return r.Type == AType }, []string{"replace A @ (1 -> 1 records)"}},
+	}
+	for _, test := range criteria {
+		t.Run(test.Name, func(t *testing.T) {
+			changes := DiffRecords(current, test.Desired, test.Managed)
+			actual := []string{}
+			for _, c := range changes {
+				actual = append(actual, c.String())
+			}
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestDiffRecordsReportsRemovedRecords(t *testing.T) {
+	current := []*DomainRecord{record(TXTType, "@", "a"), record(TXTType, "@", "b")}
+	changes := DiffRecords(current, []*DomainRecord{record(TXTType, "@", "a")}, nil)
+	assert.Len(t, changes.Removed(), 1)
+	assert.Equal(t, "b", changes.Removed()[0].Data)
+}
+
+func TestSyncDomainRecordsWritesOnlyChanges(t *testing.T) {
+	var writes []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode([]*DomainRecord{record(AType, "@", "192.0.2.1"), record(TXTType, "@", "old")})
+			return
+		}
+		writes = append(writes, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"code":"INVALID_BODY","message":"bad"}`))
+	})
+
+	changes, err := client.SyncDomainRecords(context.Background(), "", "example.com",
+		[]*DomainRecord{record(AType, "@", "192.0.2.1"), record(TXTType, "@", "new")}, nil)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, []string{"PUT /v1/domains/example.com/records/TXT/@"}, writes)
+
+	var writeErr *RecordWriteError
+	assert.ErrorAs(t, err, &writeErr)
+	assert.Equal(t, "@", writeErr.Name)
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+}
+
+func TestSyncDomainRecordsRollsBackOnFailure(t *testing.T) {
+	var criteria = []struct {
+		Name                string
+		FailRollback        bool
+		ExpectedRolledBack  []string
+		ExpectedLeftChanged []string
+	}{
+		{"Given a rollback that succeeds", false, []string{"A @"}, nil},
+		{"Given a rollback that fails", true, nil, []string{"A @"}},
+	}
+	for _, test := range criteria {
+		t.Run(test.Name, func(t *testing.T) {
+			var writes []string
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					json.NewEncoder(w).Encode([]*DomainRecord{record(AType, "@", "192.0.2.1"), record(TXTType, "@", "old")})
+					return
+				}
+				var body []*DomainRecord
+				json.NewDecoder(r.Body).Decode(&body)
+				writes = append(writes, r.Method+" "+r.URL.Path+" "+body[0].Data)
+
+				rollback := r.URL.Path == "/v1/domains/example.com/records/A/@" && body[0].Data == "192.0.2.1"
+				if r.URL.Path == "/v1/domains/example.com/records/TXT/@" || (rollback && test.FailRollback) {
+					w.WriteHeader(http.StatusUnprocessableEntity)
+					w.Write([]byte(`{"code":"INVALID_BODY","message":"bad"}`))
+				}
+			})
+
+			_, err := client.SyncDomainRecords(context.Background(), "", "example.com",
+				[]*DomainRecord{record(AType, "@", "192.0.2.2"), record(TXTType, "@", "new")}, nil)
+			assert.Equal(t, []string{
+				"PUT /v1/domains/example.com/records/A/@ 192.0.2.2",
+				"PUT /v1/domains/example.com/records/TXT/@ new",
+				"PUT /v1/domains/example.com/records/A/@ 192.0.2.1",
+			}, writes)
+
+			var rbErr *RecordRollbackError
+			assert.ErrorAs(t, err, &rbErr)
+			assert.Equal(t, test.ExpectedRolledBack, rbErr.RolledBack)
+			assert.Equal(t, test.ExpectedLeftChanged, rbErr.LeftChanged)
+
+			var writeErr *RecordWriteError
+			assert.ErrorAs(t, err, &writeErr)
+			assert.Equal(t, TXTType, writeErr.Type)
+		})
+	}
+}
@@ -2,28 +2,41 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 )
 
+const (
+	// defaultDomainPageSize is the number of domains requested per page when
+	// listing domains.
+	defaultDomainPageSize = 500
+
+	// recordPageSize is the number of records requested per page when
+	// reading a zone.
+	recordPageSize = 500
+)
+
 var (
 	pathDomainRecords       = "%s/v1/domains/%s/records"
 	pathDomainRecordsByType = "%s/v1/domains/%s/records/%s"
+	pathDomainRecordsByName = "%s/v1/domains/%s/records/%s/%s"
 	pathDomains             = "%s/v1/domains/%s"
 )
 
 // PurchaseDomain purchases the given domain for the user
-func (c *Client) PurchaseDomain(customerID string, purchase *DomainPurchase) (*DomainPurchaseReceipt, error) {
+func (c *Client) PurchaseDomain(ctx context.Context, customerID string, purchase *DomainPurchase) (*DomainPurchaseReceipt, error) {
 	domainURL := c.constructURL(pathDomains, "purchase")
 	data, err := json.Marshal(purchase)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, domainURL, bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, domainURL, bytes.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
@@ -37,9 +50,9 @@ func (c *Client) PurchaseDomain(customerID string, purchase *DomainPurchase) (*D
 }
 
 // CancelDomain cancels a domain
-func (c *Client) CancelDomain(customerID, domain string) error {
+func (c *Client) CancelDomain(ctx context.Context, customerID, domain string) error {
 	domainURL := c.constructURL(pathDomains, domain)
-	req, err := http.NewRequest(http.MethodDelete, domainURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, domainURL, nil)
 
 	if err != nil {
 		return err
@@ -49,14 +62,14 @@ func (c *Client) CancelDomain(customerID, domain string) error {
 }
 
 // UpdateDomain updates a domain
-func (c *Client) UpdateDomain(customerID, domain string, purchase *DomainPurchase) error {
+func (c *Client) UpdateDomain(ctx context.Context, customerID, domain string, purchase *DomainPurchase) error {
 	domainURL := c.constructURL(pathDomains, domain)
 	data, err := json.Marshal(purchase)
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequest(http.MethodPatch, domainURL, bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, domainURL, bytes.NewReader(data))
 	if err != nil {
 		return err
 	}
@@ -69,14 +82,14 @@ func (c *Client) UpdateDomain(customerID, domain string, purchase *DomainPurchas
 }
 
 // ValidateDomainPurchase validates the domain purchase request
-func (c *Client) ValidateDomainPurchase(customerID string, purchase *DomainPurchase) error {
+func (c *Client) ValidateDomainPurchase(ctx context.Context, customerID string, purchase *DomainPurchase) error {
 	domainURL := c.constructURL(pathDomains, "")
 	data, err := json.Marshal(purchase)
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, domainURL, bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, domainURL, bytes.NewReader(data))
 	if err != nil {
 		return err
 	}
@@ -84,27 +97,81 @@ func (c *Client) ValidateDomainPurchase(customerID string, purchase *DomainPurch
 	return c.execute(customerID, req, nil)
 }
 
-// GetDomains fetches the details for the provided domain
-func (c *Client) GetDomains(customerID string) ([]Domain, error) {
-	domainURL := c.constructURL(pathDomains, "")
-	req, err := http.NewRequest(http.MethodGet, domainURL, nil)
-
+// GetDomains fetches every domain in the account, following pagination
+// until the listing is complete.
+func (c *Client) GetDomains(ctx context.Context, customerID string, opts ...DomainListOpt) ([]Domain, error) {
+	var domains []Domain
+	err := c.EachDomainPage(ctx, customerID, func(page []Domain) bool {
+		domains = append(domains, page...)
+		return true
+	}, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	var d []Domain
-	if err := c.execute(customerID, req, &d); err != nil {
-		return nil, err
+	return domains, nil
+}
+
+// EachDomainPage fetches the account's domains a page at a time, calling fn
+// with each page in order until the listing is exhausted or fn returns false.
+func (c *Client) EachDomainPage(ctx context.Context, customerID string, fn func(page []Domain) bool, opts ...DomainListOpt) error {
+	o := domainListOptions{pageSize: defaultDomainPageSize}
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return err
+		}
 	}
 
-	return d, nil
+	query := url.Values{}
+	for _, status := range o.statuses {
+		query.Add("statuses", status)
+	}
+	for _, group := range o.statusGroups {
+		query.Add("statusGroups", group)
+	}
+	for _, include := range o.includes {
+		query.Add("includes", include)
+	}
+	query.Set("limit", strconv.Itoa(o.pageSize))
+
+	for {
+		domainURL := c.constructURL(pathDomains, "") + "?" + query.Encode()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, domainURL, nil)
+		if err != nil {
+			return err
+		}
+
+		var page []Domain
+		if err := c.execute(customerID, req, &page); err != nil {
+			return err
+		}
+		if len(page) == 0 || !fn(page) || len(page) < o.pageSize {
+			return nil
+		}
+
+		// The marker is exclusive: the next page starts after this domain.
+		query.Set("marker", page[len(page)-1].Name)
+	}
+}
+
+// VerifyCredentials makes the cheapest authenticated request available, a
+// single-item domain listing, so that bad credentials surface as an *Error
+// before any real work is attempted.
+func (c *Client) VerifyCredentials(ctx context.Context) error {
+	domainURL := c.constructURL(pathDomains, "") + "?limit=1"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, domainURL, nil)
+
+	if err != nil {
+		return err
+	}
+
+	return c.execute("", req, nil)
 }
 
 // GetDomain fetches the details for the provided domain
-func (c *Client) GetDomain(customerID, domain string) (*Domain, error) {
+func (c *Client) GetDomain(ctx context.Context, customerID, domain string) (*Domain, error) {
 	domainURL := c.constructURL(pathDomains, domain)
-	req, err := http.NewRequest(http.MethodGet, domainURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, domainURL, nil)
 
 	if err != nil {
 		return nil, err
@@ -119,63 +186,97 @@ func (c *Client) GetDomain(customerID, domain string) (*Domain, error) {
 }
 
 // GetDomainRecords fetches all of the existing records for the provided domain
-func (c *Client) GetDomainRecords(customerID, domain string) ([]*DomainRecord, error) {
-	domainURL := c.constructURL(pathDomainRecords, domain)
-	req, err := http.NewRequest(http.MethodGet, domainURL, nil)
+func (c *Client) GetDomainRecords(ctx context.Context, customerID, domain string) ([]*DomainRecord, error) {
+	return c.getRecordPages(ctx, customerID, c.constructURL(pathDomainRecords, domain))
+}
 
-	if err != nil {
-		return nil, err
-	}
+// GetDomainRecordsByType fetches the existing records of one type for the
+// provided domain
+func (c *Client) GetDomainRecordsByType(ctx context.Context, customerID, domain, t string) ([]*DomainRecord, error) {
+	return c.getRecordPages(ctx, customerID, c.constructURL(pathDomainRecordsByType, domain, t))
+}
 
+// GetDomainRecordsByTypeAndName fetches the existing records of one type and
+// name for the provided domain
+func (c *Client) GetDomainRecordsByTypeAndName(ctx context.Context, customerID, domain, t, name string) ([]*DomainRecord, error) {
+	return c.getRecordPages(ctx, customerID, c.constructURL(pathDomainRecordsByName, domain, t, url.PathEscape(name)))
+}
+
+// getRecordPages reads a record listing one page at a time, so that large
+// zones are read completely.
+func (c *Client) getRecordPages(ctx context.Context, customerID, recordsURL string) ([]*DomainRecord, error) {
 	records := make([]*DomainRecord, 0)
-	if err := c.execute(customerID, req, &records); err != nil {
-		return nil, err
+	for offset := 0; ; offset += recordPageSize {
+		query := url.Values{}
+		query.Set("offset", strconv.Itoa(offset))
+		query.Set("limit", strconv.Itoa(recordPageSize))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, recordsURL+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []*DomainRecord
+		if err := c.execute(customerID, req, &page); err != nil {
+			return nil, err
+		}
+		records = append(records, page...)
+		if len(page) < recordPageSize {
+			return records, nil
+		}
 	}
+}
 
-	return records, nil
+// UpdateDomainRecords makes the domain's records match records, writing only
+// the types and names that differ. Cancelling ctx aborts the remaining writes.
+func (c *Client) UpdateDomainRecords(ctx context.Context, customerID, domain string, records []*DomainRecord) error {
+	_, err := c.SyncDomainRecords(ctx, customerID, domain, records, nil)
+	return err
 }
 
-// UpdateDomainRecords replaces all of the existing records for the provided domain
-func (c *Client) UpdateDomainRecords(customerID, domain string, records []*DomainRecord) error {
-	for _, t := range supportedTypes {
-		typeRecords := c.domainRecordsOfType(t, records)
-		if IsDisallowed(t, typeRecords) {
-			continue
-		}
+// ReplaceDomainRecordsByType replaces all of the records of one type for the
+// provided domain
+func (c *Client) ReplaceDomainRecordsByType(ctx context.Context, customerID, domain, t string, records []*DomainRecord) error {
+	return c.writeRecords(ctx, customerID, http.MethodPut, c.constructURL(pathDomainRecordsByType, domain, t), records)
+}
 
-		msg, err := json.Marshal(typeRecords)
-		if err != nil {
-			return err
-		}
+// ReplaceDomainRecordsByTypeAndName replaces the records of one type and name
+// for the provided domain, leaving other names of the same type untouched
+func (c *Client) ReplaceDomainRecordsByTypeAndName(ctx context.Context, customerID, domain, t, name string, records []*DomainRecord) error {
+	return c.writeRecords(ctx, customerID, http.MethodPut, c.constructURL(pathDomainRecordsByName, domain, t, url.PathEscape(name)), records)
+}
 
-		buffer := bytes.NewBuffer(msg)
-		domainURL := c.constructURL(pathDomainRecordsByType, domain, t)
-		log.Println(domainURL)
-		log.Println(buffer)
+// AddDomainRecords appends records to the provided domain without touching
+// the existing ones
+func (c *Client) AddDomainRecords(ctx context.Context, customerID, domain string, records []*DomainRecord) error {
+	return c.writeRecords(ctx, customerID, http.MethodPatch, c.constructURL(pathDomainRecords, domain), records)
+}
 
-		req, err := http.NewRequest(http.MethodPut, domainURL, buffer)
-		if err != nil {
-			return err
-		}
+// DeleteDomainRecordsByTypeAndName deletes all of the records of one type and
+// name for the provided domain
+func (c *Client) DeleteDomainRecordsByTypeAndName(ctx context.Context, customerID, domain, t, name string) error {
+	domainURL := c.constructURL(pathDomainRecordsByName, domain, t, url.PathEscape(name))
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, domainURL, nil)
 
-		if err := c.execute(customerID, req, nil); err != nil {
-			return err
-		}
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return c.execute(customerID, req, nil)
 }
 
-func (c *Client) domainRecordsOfType(t string, records []*DomainRecord) []*DomainRecord {
-	typeRecords := make([]*DomainRecord, 0)
+func (c *Client) writeRecords(ctx context.Context, customerID, method, recordsURL string, records []*DomainRecord) error {
+	msg, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
 
-	for _, record := range records {
-		if strings.EqualFold(record.Type, t) {
-			typeRecords = append(typeRecords, record)
-		}
+	req, err := http.NewRequestWithContext(ctx, method, recordsURL, bytes.NewReader(msg))
+	if err != nil {
+		return err
 	}
 
-	return typeRecords
+	return c.execute(customerID, req, nil)
 }
 
 func (c *Client) constructURL(path string, v ...interface{}) string {
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// domainListHandler serves names as a marker-paginated domain listing.
+func domainListHandler(t *testing.T, names []string, requests *[]string) http.HandlerFunc {
+	sort.Strings(names)
+	return func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.URL.RawQuery)
+		q := r.URL.Query()
+		limit, err := strconv.Atoi(q.Get("limit"))
+		assert.Nil(t, err)
+
+		start := sort.SearchStrings(names, q.Get("marker"))
+		if start < len(names) && names[start] == q.Get("marker") {
+			start++
+		}
+		page := []Domain{}
+		for _, name := range names[start:] {
+			if len(page) == limit {
+				break
+			}
+			page = append(page, Domain{Name: name, Status: StatusActive})
+		}
+		json.NewEncoder(w).Encode(page)
+	}
+}
+
+func TestGetDomainsFollowsPages(t *testing.T) {
+	var requests []string
+	names := []string{"a.com", "b.com", "c.com", "d.com", "e.com"}
+	client := newTestClient(t, domainListHandler(t, names, &requests))
+
+	domains, err := client.GetDomains(context.Background(), "",
+		PageSize(2), Statuses(StatusActive), StatusGroups(StatusGroupVisible), Includes(IncludeNameServers, IncludeContacts))
+	assert.Nil(t, err)
+	assert.Len(t, domains, 5)
+	assert.Equal(t, "e.com", domains[4].Name)
+	assert.Equal(t, []string{
+		"includes=nameServers&includes=contacts&limit=2&statusGroups=VISIBLE&statuses=ACTIVE",
+		"includes=nameServers&includes=contacts&limit=2&marker=b.com&statusGroups=VISIBLE&statuses=ACTIVE",
+		"includes=nameServers&includes=contacts&limit=2&marker=d.com&statusGroups=VISIBLE&statuses=ACTIVE",
+	}, requests)
+}
+
+func TestEachDomainPageStopsEarly(t *testing.T) {
+	var requests []string
+	client := newTestClient(t, domainListHandler(t, []string{"a.com", "b.com", "c.com"}, &requests))
+
+	var seen []string
+	err := client.EachDomainPage(context.Background(), "", func(page []Domain) bool {
+		for _, d := range page {
+			seen = append(seen, d.Name)
+		}
+		return false
+	}, PageSize(2))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a.com", "b.com"}, seen)
+	assert.Len(t, requests, 1)
+
+	_, err = client.GetDomains(context.Background(), "", PageSize(0))
+	assert.NotNil(t, err)
+}
+
+func TestGetDomainRecordsReadsEveryPage(t *testing.T) {
+	total := recordPageSize + 3
+	var offsets []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		offsets = append(offsets, q.Get("offset"))
+		offset, _ := strconv.Atoi(q.Get("offset"))
+		limit, _ := strconv.Atoi(q.Get("limit"))
+
+		page := []*DomainRecord{}
+		for i := offset; i < total && i < offset+limit; i++ {
+			page = append(page, &DomainRecord{Type: TXTType, Name: "@", Data: strconv.Itoa(i), TTL: DefaultTTL})
+		}
+		json.NewEncoder(w).Encode(page)
+	})
+
+	records, err := client.GetDomainRecords(context.Background(), "", "example.com")
+	assert.Nil(t, err)
+	assert.Len(t, records, total)
+	assert.Equal(t, []string{"0", strconv.Itoa(recordPageSize)}, offsets)
+}
+
+func TestRecordEndpoints(t *testing.T) {
+	var requests []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`[]`))
+		}
+	})
+
+	ctx := context.Background()
+	records := []*DomainRecord{{Type: TXTType, Name: "_dmarc", Data: "v=DMARC1; p=none", TTL: DefaultTTL}}
+	_, err := client.GetDomainRecordsByType(ctx, "", "example.com", TXTType)
+	assert.Nil(t, err)
+	_, err = client.GetDomainRecordsByTypeAndName(ctx, "", "example.com", TXTType, "_dmarc")
+	assert.Nil(t, err)
+	assert.Nil(t, client.ReplaceDomainRecordsByType(ctx, "", "example.com", TXTType, records))
+	assert.Nil(t, client.ReplaceDomainRecordsByTypeAndName(ctx, "", "example.com", TXTType, "_dmarc", records))
+	assert.Nil(t, client.AddDomainRecords(ctx, "", "example.com", records))
+	assert.Nil(t, client.DeleteDomainRecordsByTypeAndName(ctx, "", "example.com", TXTType, "_dmarc"))
+
+	assert.Equal(t, []string{
+		"GET /v1/domains/example.com/records/TXT",
+		"GET /v1/domains/example.com/records/TXT/_dmarc",
+		"PUT /v1/domains/example.com/records/TXT",
+		"PUT /v1/domains/example.com/records/TXT/_dmarc",
+		"PATCH /v1/domains/example.com/records",
+		"DELETE /v1/domains/example.com/records/TXT/_dmarc",
+	}, requests)
+}
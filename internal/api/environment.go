@@ -0,0 +1,68 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	// EnvironmentProduction is GoDaddy's live API.
+	EnvironmentProduction = "production"
+	// EnvironmentOTE is GoDaddy's Operational Test Environment, a sandbox
+	// with its own API keys where purchases aren't billed.
+	EnvironmentOTE = "ote"
+
+	ProductionBaseURL = "https://api.godaddy.com"
+	OTEBaseURL        = "https://api.ote-godaddy.com"
+)
+
+var environmentBaseURLs = map[string]string{
+	EnvironmentProduction: ProductionBaseURL,
+	EnvironmentOTE:        OTEBaseURL,
+}
+
+// Environments lists the supported environment names.
+var Environments = []string{EnvironmentProduction, EnvironmentOTE}
+
+// BaseURLForEnvironment returns the API base URL of a named environment.
+func BaseURLForEnvironment(env string) (string, error) {
+	base, ok := environmentBaseURLs[strings.ToLower(env)]
+	if !ok {
+		return "", fmt.Errorf("environment must be one of: %s", Environments)
+	}
+	return base, nil
+}
+
+// EnvironmentForBaseURL reports which environment a base URL belongs to, or
+// an empty string for hosts that aren't GoDaddy's, such as an API gateway.
+func EnvironmentForBaseURL(base string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return ""
+	}
+	for env, envBase := range environmentBaseURLs {
+		if envURL, _ := url.Parse(envBase); strings.EqualFold(u.Hostname(), envURL.Hostname()) {
+			return env
+		}
+	}
+	return ""
+}
+
+// WithEnvironment records which environment the client talks to. It is
+// informational: it is attached to API logs but doesn't change the base URL.
+func WithEnvironment(env string) ClientOpt {
+	return func(c *Client) error {
+		if _, err := BaseURLForEnvironment(env); err != nil {
+			return err
+		}
+		c.environment = strings.ToLower(env)
+		return nil
+	}
+}
+
+// Environment returns the environment the client was configured for, or an
+// empty string if unknown.
+func (c *Client) Environment() string {
+	return c.environment
+}
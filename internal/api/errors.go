@@ -0,0 +1,178 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrUnauthorized matches errors for requests GoDaddy could not authenticate (401).
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden matches errors for requests the credentials may not perform (403).
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotFound matches errors for resources that do not exist (404).
+	ErrNotFound = errors.New("not found")
+	// ErrConflict matches errors for requests that conflict with the resource's state (409).
+	ErrConflict = errors.New("conflict")
+	// ErrInvalidRequest matches errors for requests GoDaddy rejected as invalid (422).
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrTooManyRequests matches errors for requests rejected by rate limiting (429).
+	ErrTooManyRequests = errors.New("too many requests")
+)
+
+var statusSentinels = map[int]error{
+	http.StatusUnauthorized:        ErrUnauthorized,
+	http.StatusForbidden:           ErrForbidden,
+	http.StatusNotFound:            ErrNotFound,
+	http.StatusConflict:            ErrConflict,
+	http.StatusUnprocessableEntity: ErrInvalidRequest,
+	http.StatusTooManyRequests:     ErrTooManyRequests,
+}
+
+// Error is returned for any GoDaddy API response with a 4xx or 5xx status.
+// Code, Message and Fields are decoded from GoDaddy's standard error body;
+// when the body isn't JSON (a gateway error page, say) Message falls back to
+// the HTTP status text and Body holds the raw response.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Fields     []FieldError
+	Body       []byte
+}
+
+// FieldError describes a single invalid field in a rejected request. Path is
+// the JSON path into the request body, e.g. "contactRegistrant.phone".
+type FieldError struct {
+	Code        string `json:"code"`
+	Message     string `json:"message"`
+	Path        string `json:"path"`
+	PathRelated string `json:"pathRelated"`
+}
+
+func (e *Error) Error() string {
+	var b bytes.Buffer
+	if e.Code == "" {
+		b.WriteString(fmt.Sprintf("[%d] %s", e.StatusCode, e.Message))
+	} else {
+		b.WriteString(fmt.Sprintf("[%d:%s] %s", e.StatusCode, e.Code, e.Message))
+	}
+	if len(e.Fields) == 0 {
+		return b.String()
+	}
+
+	b.WriteString(" (")
+	for i, field := range e.Fields {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(fmt.Sprintf("%s [%s]: %s", field.Path, field.Code, field.Message))
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// Is allows errors.Is to match an Error against the sentinel for its status,
+// e.g. errors.Is(err, ErrNotFound).
+func (e *Error) Is(target error) bool {
+	sentinel, ok := statusSentinels[e.StatusCode]
+	return ok && sentinel == target
+}
+
+// IsNotFound reports whether err is a GoDaddy 404 response.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsUnauthorized reports whether err is a GoDaddy 401 response.
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsForbidden reports whether err is a GoDaddy 403 response.
+func IsForbidden(err error) bool {
+	return errors.Is(err, ErrForbidden)
+}
+
+// IsTooManyRequests reports whether err is a GoDaddy 429 response.
+func IsTooManyRequests(err error) bool {
+	return errors.Is(err, ErrTooManyRequests)
+}
+
+// RecordWriteError is returned when writing a set of records fails. Name is
+// set when the write was scoped to a single name. Records is the request body
+// that was sent, so that a field path such as "records[2].data" can be traced
+// back to the record that caused it.
+type RecordWriteError struct {
+	Type    string
+	Name    string
+	Records []*DomainRecord
+	Err     error
+}
+
+func (e *RecordWriteError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("failed to update %s records named %s: %s", e.Type, e.Name, e.Err)
+	}
+	return fmt.Sprintf("failed to update %s records: %s", e.Type, e.Err)
+}
+
+func (e *RecordWriteError) Unwrap() error {
+	return e.Err
+}
+
+// RecordRollbackError is returned when a record write fails part way through
+// an update and the writes already made were reverted. Err is the write that
+// failed. RolledBack lists the record types, or "TYPE name" for name-scoped
+// writes, that were restored; LeftChanged lists those that could not be, with
+// RollbackErr the first reason why.
+type RecordRollbackError struct {
+	Err         error
+	RolledBack  []string
+	LeftChanged []string
+	RollbackErr error
+}
+
+func (e *RecordRollbackError) Error() string {
+	var b bytes.Buffer
+	b.WriteString(e.Err.Error())
+	if len(e.RolledBack) > 0 {
+		b.WriteString(fmt.Sprintf("; rolled back %s", strings.Join(e.RolledBack, ", ")))
+	}
+	if len(e.LeftChanged) > 0 {
+		b.WriteString(fmt.Sprintf("; could not roll back %s: %s", strings.Join(e.LeftChanged, ", "), e.RollbackErr))
+	}
+	return b.String()
+}
+
+func (e *RecordRollbackError) Unwrap() error {
+	return e.Err
+}
+
+// newError decodes a GoDaddy error response. It never fails: bodies that
+// aren't GoDaddy's JSON error format are preserved verbatim.
+func newError(resp *http.Response, body []byte) *Error {
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		Body:       body,
+	}
+
+	var errResp struct {
+		Code    string       `json:"code"`
+		Message string       `json:"message"`
+		Fields  []FieldError `json:"fields"`
+	}
+	if err := json.Unmarshal(body, &errResp); err == nil {
+		apiErr.Code = errResp.Code
+		apiErr.Message = errResp.Message
+		apiErr.Fields = errResp.Fields
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
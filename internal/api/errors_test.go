@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorFromJSONBody(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"code":"INVALID_BODY","message":"Request body doesn't fulfill schema","fields":[{"code":"MISMATCH_FORMAT","message":"is not a valid phone number","path":"contactRegistrant.phone"}]}`))
+	})
+
+	_, err := client.PurchaseDomain(context.Background(), "", &DomainPurchase{Domain: "example.com"})
+	var apiErr *Error
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+	assert.Equal(t, "INVALID_BODY", apiErr.Code)
+	assert.Equal(t, "contactRegistrant.phone", apiErr.Fields[0].Path)
+	assert.True(t, errors.Is(err, ErrInvalidRequest))
+	assert.Equal(t, "[422:INVALID_BODY] Request body doesn't fulfill schema (contactRegistrant.phone [MISMATCH_FORMAT]: is not a valid phone number)", err.Error())
+}
+
+func TestErrorFromHTMLBody(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`<html><body>Not Found</body></html>`))
+	})
+
+	_, err := client.GetDomain(context.Background(), "", "example.com")
+	assert.True(t, IsNotFound(err))
+	assert.False(t, IsForbidden(err))
+	assert.Equal(t, "[404] Not Found", err.Error())
+
+	var apiErr *Error
+	assert.True(t, errors.As(fmt.Errorf("wrapped: %w", err), &apiErr))
+	assert.Contains(t, string(apiErr.Body), "<html>")
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// logSubsystem is the tflog subsystem for API traffic. Its level can be set
+// independently of the provider's with logLevelEnv.
+const (
+	logSubsystem = "godaddy_api"
+	logLevelEnv  = "TF_LOG_PROVIDER_GODADDY_API"
+)
+
+// redacted replaces masked values in logged bodies.
+const redacted = "***"
+
+// sensitiveFields are the JSON keys whose values are masked in logged
+// request and response bodies: contact details of registrants and other
+// domain contacts, the consenting IP address, and transfer auth codes.
+var sensitiveFields = map[string]bool{
+	"addressmailing": true,
+	"agreedby":       true,
+	"authcode":       true,
+	"email":          true,
+	"fax":            true,
+	"jobtitle":       true,
+	"namefirst":      true,
+	"namelast":       true,
+	"namemiddle":     true,
+	"organization":   true,
+	"phone":          true,
+}
+
+// logContext registers the API log subsystem on ctx with the client's
+// credentials masked wherever they might appear.
+func (c *Client) logContext(ctx context.Context) context.Context {
+	ctx = tflog.NewSubsystem(ctx, logSubsystem, tflog.WithLevelFromEnv(logLevelEnv))
+	ctx = tflog.SubsystemMaskFieldValuesWithFieldKeys(ctx, logSubsystem, strings.ToLower(headerAuthorization))
+	for _, secret := range []string{c.key, c.secret} {
+		if secret == "" {
+			continue
+		}
+		ctx = tflog.SubsystemMaskAllFieldValuesStrings(ctx, logSubsystem, secret)
+		ctx = tflog.SubsystemMaskMessageStrings(ctx, logSubsystem, secret)
+	}
+	return ctx
+}
+
+// logRequestBody logs the redacted request payload at TRACE.
+func logRequestBody(ctx context.Context, req *http.Request) {
+	if req.GetBody == nil {
+		return
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil || len(data) == 0 {
+		return
+	}
+	tflog.SubsystemTrace(ctx, logSubsystem, "API request body", map[string]interface{}{
+		"body": redactBody(data),
+	})
+}
+
+// logResponse logs the outcome of a request at DEBUG and its redacted body
+// at TRACE.
+func logResponse(ctx context.Context, resp *http.Response, body []byte, duration time.Duration) {
+	fields := map[string]interface{}{
+		"status":      resp.StatusCode,
+		"duration_ms": duration.Milliseconds(),
+	}
+	if id := resp.Header.Get(headerRequestID); id != "" {
+		fields["response_request_id"] = id
+	}
+	tflog.SubsystemDebug(ctx, logSubsystem, "received API response", fields)
+
+	if len(body) > 0 {
+		tflog.SubsystemTrace(ctx, logSubsystem, "API response body", map[string]interface{}{
+			"body": redactBody(body),
+		})
+	}
+}
+
+// redactBody masks sensitiveFields anywhere in a JSON document. Bodies that
+// aren't JSON, such as gateway error pages, are returned unchanged.
+func redactBody(body []byte) string {
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return string(body)
+	}
+	out, err := json.Marshal(redactValue(doc))
+	if err != nil {
+		return string(body)
+	}
+	return string(out)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			if sensitiveFields[strings.ToLower(k)] {
+				val[k] = redacted
+				continue
+			}
+			val[k] = redactValue(child)
+		}
+	case []interface{}:
+		for i, child := range val {
+			val[i] = redactValue(child)
+		}
+	}
+	return v
+}
+
+// newRequestID generates the X-Request-Id sent with each request, which
+// GoDaddy support can use to trace a call.
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactBody(t *testing.T) {
+	purchase := &DomainPurchase{
+		Domain:  "example.com",
+		Consent: &Consent{AgreedAt: "2024-01-01T00:00:00Z", AgreedBy: "192.0.2.1"},
+		RegistrantContact: &Contact{
+			FirstName: "Jane",
+			LastName:  "Doe",
+			Email:     "jane@example.com",
+			Phone:     "+1.5555555555",
+			Address:   &Address{Line1: "1234 Main St", City: "Alameda"},
+		},
+		NameServers: []string{"ns1.example.com"},
+	}
+	body, err := json.Marshal(purchase)
+	assert.Nil(t, err)
+
+	out := redactBody(body)
+	for _, secret := range []string{"Jane", "Doe", "jane@example.com", "5555555555", "1234 Main St", "Alameda", "192.0.2.1"} {
+		assert.NotContains(t, out, secret)
+	}
+	assert.Contains(t, out, "example.com")
+	assert.Contains(t, out, "ns1.example.com")
+	assert.Contains(t, out, "2024-01-01T00:00:00Z")
+
+	assert.Equal(t, "<html>Bad Gateway</html>", redactBody([]byte("<html>Bad Gateway</html>")))
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout   = 30 * time.Second
+	defaultUserAgent = "terraform-provider-godaddy"
+)
+
+// ClientOpt provides support for setting optional client parameters
+type ClientOpt func(*Client) error
+
+// WithHTTPClient sends requests through the given HTTP client instead of the
+// default one. The client is copied, so the caller's value is not modified
+// by WithTimeout or WithTransportMiddleware.
+func WithHTTPClient(client *http.Client) ClientOpt {
+	return func(c *Client) error {
+		if client == nil {
+			return errors.New("http client must not be nil")
+		}
+		c.client = client
+		return nil
+	}
+}
+
+// WithTimeout sets the overall timeout for a single HTTP request. It
+// overrides the timeout of a client supplied with WithHTTPClient.
+func WithTimeout(timeout time.Duration) ClientOpt {
+	return func(c *Client) error {
+		if timeout <= 0 {
+			return errors.New("timeout must be a positive duration")
+		}
+		c.timeout = timeout
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) ClientOpt {
+	return func(c *Client) error {
+		if strings.TrimSpace(userAgent) == "" {
+			return errors.New("user agent must not be empty")
+		}
+		c.userAgent = userAgent
+		return nil
+	}
+}
+
+// WithTransportMiddleware wraps the HTTP transport. Middleware is applied in
+// the order given, so the first one sees each request first.
+func WithTransportMiddleware(middleware func(http.RoundTripper) http.RoundTripper) ClientOpt {
+	return func(c *Client) error {
+		if middleware == nil {
+			return errors.New("transport middleware must not be nil")
+		}
+		c.middleware = append(c.middleware, middleware)
+		return nil
+	}
+}
+
+// WithRetryPolicy replaces the policy used to retry throttled and failed
+// requests.
+func WithRetryPolicy(policy RetryPolicy) ClientOpt {
+	return func(c *Client) error {
+		if policy.MaxRetries < 0 {
+			return errors.New("max retries must not be negative")
+		}
+		c.retry = policy
+		return nil
+	}
+}
+
+// WithRateLimit sets the number of requests per minute. Limits are shared by
+// every client using the same API key; see sharedRateLimiter. When adaptive is
+// set, the client slows down further after GoDaddy responds with 429 and
+// recovers gradually as requests succeed.
+func WithRateLimit(requestsPerMinute int, adaptive bool) ClientOpt {
+	return func(c *Client) error {
+		if requestsPerMinute < 1 {
+			return errors.New("requests per minute must be at least 1")
+		}
+		c.requestsPerMinute = requestsPerMinute
+		c.adaptiveRateLimit = adaptive
+		return nil
+	}
+}
+
+// WithProxyURL sends requests through the given HTTP(S) proxy. Without it,
+// the proxy is taken from the HTTPS_PROXY, HTTP_PROXY and NO_PROXY
+// environment variables.
+func WithProxyURL(proxyURL string) ClientOpt {
+	return func(c *Client) error {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return errors.New("invalid proxy URL. expected format: scheme://host[:port]")
+		}
+		c.proxy = u
+		return nil
+	}
+}
+
+// WithCACertificates trusts the PEM-encoded certificates in addition to the
+// system roots, e.g. for a TLS-intercepting corporate proxy.
+func WithCACertificates(pem []byte) ClientOpt {
+	return func(c *Client) error {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("no PEM-encoded certificates found in CA bundle")
+		}
+		c.tlsConfig = c.tlsOptions()
+		c.tlsConfig.RootCAs = pool
+		return nil
+	}
+}
+
+// WithInsecureSkipVerify disables verification of the server's TLS
+// certificate. It should only be used for testing.
+func WithInsecureSkipVerify(skip bool) ClientOpt {
+	return func(c *Client) error {
+		c.tlsConfig = c.tlsOptions()
+		c.tlsConfig.InsecureSkipVerify = skip
+		return nil
+	}
+}
+
+// tlsOptions returns the TLS config being built up by the options.
+func (c *Client) tlsOptions() *tls.Config {
+	if c.tlsConfig == nil {
+		return &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return c.tlsConfig
+}
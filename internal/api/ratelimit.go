@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRequestsPerMinute is GoDaddy's documented limit of 60 requests
+	// per minute per endpoint. It is applied across all endpoints, which
+	// keeps us well clear of throttling.
+	DefaultRequestsPerMinute = 60
+
+	// rateLimitBurst is the bucket capacity. GoDaddy enforces its limit over
+	// a rolling minute, so we only allow a single request to be saved up;
+	// anything larger could exceed the limit across window boundaries.
+	rateLimitBurst = 1
+
+	// adaptiveMinRate is the floor, in requests per second, that adaptive
+	// throttling will back off to.
+	adaptiveMinRate = 1.0 / 10
+)
+
+// rateLimiter is a token bucket. Callers reserve a token under the lock and
+// sleep outside of it, so waiters are served in the order they arrived and
+// a sleeping request never blocks others from queueing.
+type rateLimiter struct {
+	mu       sync.Mutex
+	maxRate  float64 // configured tokens per second
+	rate     float64 // tokens per second in effect; below maxRate while adapting
+	tokens   float64
+	last     time.Time
+	adaptive bool
+}
+
+func newRateLimiter(requestsPerMinute int, adaptive bool) *rateLimiter {
+	rate := float64(requestsPerMinute) / 60
+	return &rateLimiter{
+		maxRate:  rate,
+		rate:     rate,
+		tokens:   rateLimitBurst,
+		last:     time.Now(),
+		adaptive: adaptive,
+	}
+}
+
+var limiters = struct {
+	sync.Mutex
+	byKey map[string]*rateLimiter
+}{byKey: map[string]*rateLimiter{}}
+
+// sharedRateLimiter returns the process-wide limiter for an API key, so that
+// provider aliases sharing credentials also share their quota. When aliases
+// disagree on the limit, the most conservative setting wins.
+func sharedRateLimiter(key string, requestsPerMinute int, adaptive bool) *rateLimiter {
+	sum := sha256.Sum256([]byte(key))
+	id := hex.EncodeToString(sum[:])
+
+	limiters.Lock()
+	defer limiters.Unlock()
+
+	l, ok := limiters.byKey[id]
+	if !ok {
+		l = newRateLimiter(requestsPerMinute, adaptive)
+		limiters.byKey[id] = l
+		return l
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if rate := float64(requestsPerMinute) / 60; rate < l.maxRate {
+		l.maxRate = rate
+		l.rate = math.Min(l.rate, rate)
+	}
+	l.adaptive = l.adaptive || adaptive
+	return l
+}
+
+// wait blocks until a request may be sent or ctx is cancelled.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	delay := l.reserve(time.Now())
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait before
+// using it.
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.advance(now)
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// throttled is called when GoDaddy rejects a request with 429. In adaptive
+// mode the rate is halved and queued requests are pushed back by retryAfter.
+func (l *rateLimiter) throttled(retryAfter time.Duration) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.adaptive {
+		return
+	}
+
+	l.advance(time.Now())
+	l.rate = math.Max(l.rate/2, math.Min(adaptiveMinRate, l.maxRate))
+	if pause := retryAfter.Seconds() * l.rate; pause > 0 {
+		l.tokens = math.Min(l.tokens, 0) - pause
+	}
+}
+
+// succeeded is called after every non-429 response. In adaptive mode it
+// gradually restores the configured rate.
+func (l *rateLimiter) succeeded() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.adaptive || l.rate >= l.maxRate {
+		return
+	}
+
+	l.advance(time.Now())
+	l.rate = math.Min(l.rate+l.maxRate/20, l.maxRate)
+}
+
+// advance refills the bucket for the time elapsed since the last call.
+func (l *rateLimiter) advance(now time.Time) {
+	if elapsed := now.Sub(l.last).Seconds(); elapsed > 0 {
+		l.tokens = math.Min(rateLimitBurst, l.tokens+elapsed*l.rate)
+		l.last = now
+	}
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterQueuesInOrder(t *testing.T) {
+	l := newRateLimiter(60, false)
+	now := l.last
+
+	assert.Equal(t, time.Duration(0), l.reserve(now))
+	assert.Equal(t, 1*time.Second, l.reserve(now))
+	assert.Equal(t, 2*time.Second, l.reserve(now))
+
+	// Once the queue has drained, a request spaced out by the rate is free.
+	assert.Equal(t, time.Duration(0), l.reserve(now.Add(4*time.Second)))
+}
+
+func TestRateLimiterAdapts(t *testing.T) {
+	l := newRateLimiter(60, true)
+	l.throttled(0)
+	assert.Equal(t, 0.5, l.rate)
+
+	for i := 0; i < 100; i++ {
+		l.succeeded()
+	}
+	assert.Equal(t, 1.0, l.rate)
+
+	fixed := newRateLimiter(60, false)
+	fixed.throttled(10 * time.Second)
+	assert.Equal(t, 1.0, fixed.rate)
+}
+
+func TestSharedRateLimiterKeepsLowestLimit(t *testing.T) {
+	a := sharedRateLimiter("shared-key", 60, false)
+	b := sharedRateLimiter("shared-key", 30, true)
+	c := sharedRateLimiter("other-key", 60, false)
+
+	assert.Same(t, a, b)
+	assert.NotSame(t, a, c)
+	assert.Equal(t, 0.5, a.maxRate)
+	assert.True(t, a.adaptive)
+}
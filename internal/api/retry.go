@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// retryBaseDelay is the delay before the first retry; each subsequent retry
+// doubles it, up to the policy's MaxBackoff.
+var retryBaseDelay = 1 * time.Second
+
+// DefaultRetryPolicy is used by clients that have not been given an explicit
+// policy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MaxBackoff: 30 * time.Second,
+}
+
+// RetryPolicy controls how failed requests are retried. Requests are retried
+// with exponential backoff and jitter when GoDaddy answers 429 or 5xx, or when
+// the request fails before a response is received.
+//
+// Only idempotent methods (GET, HEAD, PUT, DELETE) are retried on 5xx and
+// transport errors. POST and PATCH requests such as PurchaseDomain may have
+// been processed even though the call failed, so they are only retried on 429,
+// which GoDaddy returns before acting on the request.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the initial attempt. Zero
+	// disables retries.
+	MaxRetries int
+	// MaxBackoff caps the computed delay between attempts. A Retry-After
+	// header or retryAfterSec field larger than this is still honored.
+	MaxBackoff time.Duration
+}
+
+// retryable reports whether a request that failed with the given status
+// (zero for transport errors) should be attempted again.
+func (p RetryPolicy) retryable(req *http.Request, status, attempt int) bool {
+	if attempt >= p.MaxRetries {
+		return false
+	}
+	if status == http.StatusTooManyRequests {
+		return true
+	}
+	if !isIdempotent(req.Method) {
+		return false
+	}
+	return status == 0 || status >= http.StatusInternalServerError
+}
+
+// wait sleeps before the next attempt, returning early with the context's
+// error if it is cancelled.
+func (p RetryPolicy) wait(ctx context.Context, attempt int, retryAfter time.Duration) error {
+	timer := time.NewTimer(p.backoff(attempt, retryAfter))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// backoff computes an exponential delay with jitter for the given attempt.
+// A server-provided retryAfter takes precedence when it is longer.
+func (p RetryPolicy) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	delay := retryBaseDelay << attempt
+	if p.MaxBackoff > 0 && (delay <= 0 || delay > p.MaxBackoff) {
+		delay = p.MaxBackoff
+	}
+	if delay > 0 {
+		// Equal jitter: keep half of the delay and randomize the rest so that
+		// concurrent resources don't retry in lockstep.
+		half := delay / 2
+		delay = half + time.Duration(rand.Int63n(int64(half)+1))
+	}
+	if retryAfter > delay {
+		return retryAfter
+	}
+	return delay
+}
+
+// retryAfter extracts the server's requested delay from either the
+// Retry-After header or the retryAfterSec field of a GoDaddy error body.
+func retryAfter(resp *http.Response, body []byte) time.Duration {
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+			return time.Duration(secs) * time.Second
+		}
+		if at, err := http.ParseTime(v); err == nil {
+			return time.Until(at)
+		}
+	}
+
+	var errResp struct {
+		RetryAfterSec int `json:"retryAfterSec"`
+	}
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.RetryAfterSec > 0 {
+		return time.Duration(errResp.RetryAfterSec) * time.Second
+	}
+	return 0
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
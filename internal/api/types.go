@@ -66,6 +66,13 @@ const (
 	StatusActive    = "ACTIVE"
 	StatusCancelled = "CANCELLED"
 
+	StatusGroupInactive          = "INACTIVE"
+	StatusGroupPreRegistration   = "PRE_REGISTRATION"
+	StatusGroupRedemption        = "REDEMPTION"
+	StatusGroupRenewable         = "RENEWABLE"
+	StatusGroupVerificationICANN = "VERIFICATION_ICANN"
+	StatusGroupVisible           = "VISIBLE"
+
 	Ptr       = "@"
 	AType     = "A"
 	AAAAType  = "AAAA"
@@ -78,6 +85,13 @@ const (
 	TXTType   = "TXT"
 )
 
+// Domain includes that may be requested from domain listings with Includes.
+const (
+	IncludeAuthCode    = "authCode"
+	IncludeContacts    = "contacts"
+	IncludeNameServers = "nameServers"
+)
+
 var supportedTypes = []string{
 	AType, AAAAType, CAAType, CNameType, MXType, NSType, SOAType, SRVType, TXTType,
 }
@@ -236,6 +250,57 @@ type Domain struct {
 	YearsLeased       int      `json:"period,omitempty"`
 	EnablePrivacy     bool     `json:"privacy,omitempty"`
 	AutoRenew         bool     `json:"renewAuto,omitempty"`
+	Locked            bool     `json:"locked,omitempty"`
+	Expires           string   `json:"expires,omitempty"`
+	AuthCode          string   `json:"authCode,omitempty"`
+}
+
+// DomainListOpt provides support for filtering and paging domain listings
+type DomainListOpt func(*domainListOptions) error
+
+type domainListOptions struct {
+	statuses     []string
+	statusGroups []string
+	includes     []string
+	pageSize     int
+}
+
+// Statuses limits a domain listing to domains with one of the given
+// statuses, e.g. StatusActive.
+func Statuses(statuses ...string) DomainListOpt {
+	return func(o *domainListOptions) error {
+		o.statuses = append(o.statuses, statuses...)
+		return nil
+	}
+}
+
+// StatusGroups limits a domain listing to domains in one of the given
+// status groups, e.g. StatusGroupVisible.
+func StatusGroups(groups ...string) DomainListOpt {
+	return func(o *domainListOptions) error {
+		o.statusGroups = append(o.statusGroups, groups...)
+		return nil
+	}
+}
+
+// Includes asks for optional details that domain listings leave out by
+// default: IncludeAuthCode, IncludeContacts or IncludeNameServers.
+func Includes(includes ...string) DomainListOpt {
+	return func(o *domainListOptions) error {
+		o.includes = append(o.includes, includes...)
+		return nil
+	}
+}
+
+// PageSize sets how many domains are requested per page
+func PageSize(size int) DomainListOpt {
+	return func(o *domainListOptions) error {
+		if size < 1 {
+			return fmt.Errorf("page size must be at least 1, got %d", size)
+		}
+		o.pageSize = size
+		return nil
+	}
 }
 
 // DomainRecord encapsulates a domain record resource
@@ -275,7 +340,7 @@ func NewDomainRecord(name, t, data string, ttl int, opts ...DomainRecordOpt) (*D
 	if ttl < 0 {
 		return nil, errors.New("ttl must be a positive value")
 	}
-	if !isSupportedType(t) {
+	if !IsSupportedType(t) {
 		return nil, fmt.Errorf("type must be one of: %s", supportedTypes)
 	}
 	dr := &DomainRecord{
@@ -360,6 +425,8 @@ func ValidateData(t, data string) error {
 	switch t {
 	case SRVType:
 		return nil
+	case CAAType:
+		return validateCAAData(data)
 	case TXTType:
 		if len(data) < 0 || len(data) > 512 {
 			return errors.New("TXT data must be between 0..512 characters in length")
@@ -406,10 +473,11 @@ func IsDefaultNSRecord(record *DomainRecord) bool {
 
 // IsDisallowed prevents empty NS|SOA record lists from being propagated, which is disallowed
 func IsDisallowed(t string, records []*DomainRecord) bool {
-	return len(records) == 0 && strings.EqualFold(t, NSType) || strings.EqualFold(t, SOAType) || strings.EqualFold(t, CAAType)
+	return len(records) == 0 && strings.EqualFold(t, NSType) || strings.EqualFold(t, SOAType)
 }
 
-func isSupportedType(recType string) bool {
+// IsSupportedType reports whether recType is a record type GoDaddy supports
+func IsSupportedType(recType string) bool {
 	for _, t := range supportedTypes {
 		if t == recType {
 			return true
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+var (
+	pathShopper         = "%s/v1/shoppers/%s?includes=customerId"
+	pathV2Domain        = "%s/v2/customers/%s/domains/%s"
+	pathV2DomainActions = "%s/v2/customers/%s/domains/%s/actions"
+	pathV2DomainAction  = "%s/v2/customers/%s/domains/%s/actions/%s"
+)
+
+var errShopperIDRequired = errors.New("a customer (shopper) ID is required to use the GoDaddy v2 API")
+
+// Domain includes that may be requested from GetDomainV2, as well as
+// IncludeContacts.
+const (
+	IncludeActions             = "actions"
+	IncludeDNSSECRecords       = "dnssecRecords"
+	IncludeRegistryStatusCodes = "registryStatusCodes"
+)
+
+// Statuses of an asynchronous domain action.
+const (
+	ActionStatusAccepted  = "ACCEPTED"
+	ActionStatusAwaiting  = "AWAITING"
+	ActionStatusCancelled = "CANCELLED"
+	ActionStatusFailed    = "FAILED"
+	ActionStatusPending   = "PENDING"
+	ActionStatusSuccess   = "SUCCESS"
+)
+
+// customerIDCache maps shopper IDs to v2 customer IDs. The mapping never
+// changes, so it is kept for the life of the Client.
+type customerIDCache struct {
+	mu        sync.Mutex
+	byShopper map[string]string
+}
+
+func (c *customerIDCache) get(shopperID string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id, ok := c.byShopper[shopperID]
+	return id, ok
+}
+
+func (c *customerIDCache) put(shopperID, customerID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.byShopper == nil {
+		c.byShopper = map[string]string{}
+	}
+	c.byShopper[shopperID] = customerID
+}
+
+// Shopper is a GoDaddy shopper account
+type Shopper struct {
+	ShopperID  string `json:"shopperId"`
+	CustomerID string `json:"customerId"`
+}
+
+// DomainV2 is a domain as returned by the v2 API. Actions, Contacts,
+// DNSSECRecords and RegistryStatusCodes are only populated when requested
+// through the includes of GetDomainV2.
+type DomainV2 struct {
+	ID                  int64           `json:"domainId"`
+	Name                string          `json:"domain"`
+	Status              string          `json:"status"`
+	ExpiresAt           string          `json:"expiresAt,omitempty"`
+	Locked              bool            `json:"locked"`
+	Privacy             bool            `json:"privacy"`
+	AutoRenew           bool            `json:"renewAuto"`
+	NameServers         []string        `json:"nameServers,omitempty"`
+	Actions             []*DomainAction `json:"actions,omitempty"`
+	Contacts            *DomainContacts `json:"contacts,omitempty"`
+	DNSSECRecords       []*DNSSECRecord `json:"dnssecRecords,omitempty"`
+	RegistryStatusCodes []string        `json:"registryStatusCodes,omitempty"`
+}
+
+// DomainContacts are the contacts of a v2 domain
+type DomainContacts struct {
+	Admin      *Contact `json:"admin,omitempty"`
+	Billing    *Contact `json:"billing,omitempty"`
+	Registrant *Contact `json:"registrant,omitempty"`
+	Tech       *Contact `json:"tech,omitempty"`
+}
+
+// DNSSECRecord is a DS or DNSKEY record published for a domain
+type DNSSECRecord struct {
+	Algorithm        string `json:"algorithm"`
+	DigestType       string `json:"digestType,omitempty"`
+	Digest           string `json:"digest,omitempty"`
+	Flags            string `json:"flags,omitempty"`
+	KeyTag           int    `json:"keyTag,omitempty"`
+	MaxSignatureLife int    `json:"maxSignatureLife,omitempty"`
+	PublicKey        string `json:"publicKey,omitempty"`
+}
+
+// DomainAction is an asynchronous operation on a domain, such as a DNSSEC
+// change, that GoDaddy completes in the background.
+type DomainAction struct {
+	Type        string        `json:"type"`
+	Origination string        `json:"origination,omitempty"`
+	Status      string        `json:"status"`
+	CreatedAt   string        `json:"createdAt,omitempty"`
+	StartedAt   string        `json:"startedAt,omitempty"`
+	CompletedAt string        `json:"completedAt,omitempty"`
+	ModifiedAt  string        `json:"modifiedAt,omitempty"`
+	Reason      *ActionReason `json:"reason,omitempty"`
+}
+
+// ActionReason explains why a DomainAction failed
+type ActionReason struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// Done reports whether the action has finished, successfully or not.
+func (a *DomainAction) Done() bool {
+	switch a.Status {
+	case ActionStatusCancelled, ActionStatusFailed, ActionStatusSuccess:
+		return true
+	}
+	return false
+}
+
+// CustomerID resolves the v2 customer ID for a shopper ID. GoDaddy's v2 API
+// addresses accounts by a customer ID (a UUID) rather than the shopper ID
+// used by v1 and the X-Shopper-Id header; the two are linked through the
+// shoppers API. Successful lookups are cached, so repeated calls for the same
+// shopper are free.
+func (c *Client) CustomerID(ctx context.Context, shopperID string) (string, error) {
+	shopperID = strings.TrimSpace(shopperID)
+	if shopperID == "" {
+		return "", errShopperIDRequired
+	}
+	if id, ok := c.customers.get(shopperID); ok {
+		return id, nil
+	}
+
+	shopperURL := c.constructURL(pathShopper, url.PathEscape(shopperID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, shopperURL, nil)
+
+	if err != nil {
+		return "", err
+	}
+
+	var s Shopper
+	if err := c.execute(shopperID, req, &s); err != nil {
+		return "", err
+	}
+	if s.CustomerID == "" {
+		return "", fmt.Errorf("GoDaddy returned no customer ID for shopper %s", shopperID)
+	}
+
+	c.customers.put(shopperID, s.CustomerID)
+	return s.CustomerID, nil
+}
+
+// GetDomainV2 fetches the v2 details for the provided domain, along with any
+// of the Include* sections requested.
+func (c *Client) GetDomainV2(ctx context.Context, shopperID, domain string, includes ...string) (*DomainV2, error) {
+	domainURL, err := c.v2URL(ctx, shopperID, pathV2Domain, domain)
+	if err != nil {
+		return nil, err
+	}
+	if len(includes) > 0 {
+		domainURL += "?" + url.Values{"includes": includes}.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, domainURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	d := new(DomainV2)
+	if err := c.execute(shopperID, req, d); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// GetDomainActions fetches the recent asynchronous actions for a domain
+func (c *Client) GetDomainActions(ctx context.Context, shopperID, domain string) ([]*DomainAction, error) {
+	actionsURL, err := c.v2URL(ctx, shopperID, pathV2DomainActions, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, actionsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := make([]*DomainAction, 0)
+	if err := c.execute(shopperID, req, &actions); err != nil {
+		return nil, err
+	}
+
+	return actions, nil
+}
+
+// GetDomainAction fetches the most recent action of the given type for a
+// domain, e.g. "DNSSEC_CREATE".
+func (c *Client) GetDomainAction(ctx context.Context, shopperID, domain, actionType string) (*DomainAction, error) {
+	actionURL, err := c.v2URL(ctx, shopperID, pathV2DomainAction, domain, actionType)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, actionURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	a := new(DomainAction)
+	if err := c.execute(shopperID, req, a); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// v2URL builds a URL for a v2 endpoint whose first argument after the base
+// URL is the customer ID, resolving it from shopperID.
+func (c *Client) v2URL(ctx context.Context, shopperID, path string, v ...string) (string, error) {
+	customerID, err := c.CustomerID(ctx, shopperID)
+	if err != nil {
+		return "", err
+	}
+
+	args := []interface{}{url.PathEscape(customerID)}
+	for _, s := range v {
+		args = append(args, url.PathEscape(s))
+	}
+	return c.constructURL(path, args...), nil
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomerIDIsResolvedOnce(t *testing.T) {
+	var lookups int32
+	var paths []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/shoppers/1234" {
+			atomic.AddInt32(&lookups, 1)
+			assert.Equal(t, "customerId", r.URL.Query().Get("includes"))
+			w.Write([]byte(`{"shopperId":"1234","customerId":"2a4e-uuid"}`))
+			return
+		}
+		paths = append(paths, r.URL.RequestURI())
+		w.Write([]byte(`{"domainId":1,"domain":"example.com","status":"ACTIVE","actions":[{"type":"DNSSEC_CREATE","status":"PENDING"}]}`))
+	})
+
+	for i := 0; i < 2; i++ {
+		d, err := client.GetDomainV2(context.Background(), "1234", "example.com", IncludeActions)
+		assert.Nil(t, err)
+		assert.Equal(t, "example.com", d.Name)
+		assert.False(t, d.Actions[0].Done())
+	}
+	assert.EqualValues(t, 1, lookups)
+	assert.Equal(t, "/v2/customers/2a4e-uuid/domains/example.com?includes=actions", paths[0])
+}
+
+func TestCustomerIDErrors(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":"NOT_FOUND","message":"Shopper not found"}`))
+	})
+
+	_, err := client.CustomerID(context.Background(), "")
+	assert.ErrorIs(t, err, errShopperIDRequired)
+
+	_, err = client.GetDomainActions(context.Background(), "1234", "example.com")
+	assert.True(t, IsNotFound(err))
+	_, ok := client.customers.get("1234")
+	assert.False(t, ok)
+}
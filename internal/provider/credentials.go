@@ -0,0 +1,195 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	defaultProfile = "default"
+
+	// credentialProcessTimeout bounds how long a credential_process command
+	// may run before Configure gives up on it.
+	credentialProcessTimeout = 1 * time.Minute
+)
+
+// credentials is a resolved API key pair and a description of where it came
+// from, for logs and diagnostics.
+type credentials struct {
+	key    string
+	secret string
+	source string
+}
+
+// credentialSettings are the provider settings that select where credentials
+// come from, after environment variable fallbacks have been applied.
+type credentialSettings struct {
+	key               string
+	secret            string
+	profile           string
+	credentialsFile   string
+	credentialProcess string
+}
+
+// resolveCredentials finds the API key and secret, trying in order:
+//
+//  1. key and secret from the provider configuration or GODADDY_API_KEY and
+//     GODADDY_API_SECRET,
+//  2. the provider's credential_process command,
+//  3. the selected profile in the shared credentials file, which may itself
+//     name a credential_process.
+//
+// It returns empty credentials without an error when nothing is configured,
+// leaving the caller to report what is missing.
+func resolveCredentials(ctx context.Context, s credentialSettings) (credentials, error) {
+	if s.key != "" || s.secret != "" {
+		return credentials{key: s.key, secret: s.secret, source: "provider configuration"}, nil
+	}
+
+	if s.credentialProcess != "" {
+		creds, err := runCredentialProcess(ctx, s.credentialProcess)
+		if err != nil {
+			return credentials{}, err
+		}
+		creds.source = "credential_process"
+		return creds, nil
+	}
+
+	// A missing file or default profile is fine; a profile that was asked for
+	// by name must exist.
+	name, explicit := s.profile, s.profile != ""
+	if !explicit {
+		name = defaultProfile
+	}
+
+	profiles, err := readCredentialsFile(s.credentialsFile)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return credentials{}, nil
+	}
+	if err != nil {
+		return credentials{}, fmt.Errorf("reading shared credentials file: %w", err)
+	}
+
+	profile, ok := profiles[name]
+	if !ok {
+		if !explicit {
+			return credentials{}, nil
+		}
+		return credentials{}, fmt.Errorf("profile %q not found in %s", name, s.credentialsFile)
+	}
+
+	source := fmt.Sprintf("profile %q in %s", name, s.credentialsFile)
+	if process := profile["credential_process"]; process != "" {
+		creds, err := runCredentialProcess(ctx, process)
+		if err != nil {
+			return credentials{}, fmt.Errorf("%s: %w", source, err)
+		}
+		creds.source = "credential_process of " + source
+		return creds, nil
+	}
+	return credentials{key: profile["key"], secret: profile["secret"], source: source}, nil
+}
+
+// defaultCredentialsFile returns ~/.godaddy/credentials, or an empty string
+// if the home directory can't be determined.
+func defaultCredentialsFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".godaddy", "credentials")
+}
+
+// readCredentialsFile parses an INI-style credentials file:
+//
+//	[default]
+//	key    = ...
+//	secret = ...
+//
+//	[reseller]
+//	credential_process = vault-godaddy reseller
+func readCredentialsFile(name string) (map[string]map[string]string, error) {
+	if name == "" {
+		return nil, os.ErrNotExist
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseCredentials(f)
+}
+
+func parseCredentials(r io.Reader) (map[string]map[string]string, error) {
+	profiles := map[string]map[string]string{}
+	var current map[string]string
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(strings.TrimPrefix(line[1:len(line)-1], "profile "))
+			current = profiles[name]
+			if current == nil {
+				current = map[string]string{}
+				profiles[name] = current
+			}
+			continue
+		}
+
+		k, v, ok := strings.Cut(line, "=")
+		if !ok || current == nil {
+			return nil, fmt.Errorf("line %d: expected a [profile] header or key = value", lineNo)
+		}
+		current[strings.ToLower(strings.TrimSpace(k))] = strings.TrimSpace(v)
+	}
+	return profiles, scanner.Err()
+}
+
+// runCredentialProcess runs command through the system shell and reads
+// credentials from the JSON object it prints to stdout:
+//
+//	{"key": "...", "secret": "..."}
+func runCredentialProcess(ctx context.Context, command string) (credentials, error) {
+	ctx, cancel := context.WithTimeout(ctx, credentialProcessTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return credentials{}, fmt.Errorf("credential_process failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var out struct {
+		Key    string `json:"key"`
+		Secret string `json:"secret"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return credentials{}, fmt.Errorf("credential_process printed invalid JSON: %w", err)
+	}
+	if out.Key == "" || out.Secret == "" {
+		return credentials{}, errors.New("credential_process output must include both key and secret")
+	}
+	return credentials{key: out.Key, secret: out.Secret}, nil
+}
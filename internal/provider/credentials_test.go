@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testCredentialsFile = `
+# comment
+[default]
+key    = default-key
+secret = default-secret
+
+[profile reseller]
+Key    = reseller-key
+Secret = reseller-secret
+
+[process]
+credential_process = echo '{"key":"process-key","secret":"process-secret"}'
+`
+
+func TestParseCredentials(t *testing.T) {
+	profiles, err := parseCredentials(strings.NewReader(testCredentialsFile))
+	assert.NoError(t, err)
+	assert.Equal(t, "default-key", profiles["default"]["key"])
+	assert.Equal(t, "reseller-secret", profiles["reseller"]["secret"])
+	assert.Contains(t, profiles["process"]["credential_process"], "process-key")
+
+	_, err = parseCredentials(strings.NewReader("key = orphan\n"))
+	assert.Error(t, err)
+}
+
+func TestResolveCredentials(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "credentials")
+	assert.NoError(t, os.WriteFile(file, []byte(testCredentialsFile), 0o600))
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	var criteria = []struct {
+		Name           string
+		Settings       credentialSettings
+		ExpectedKey    string
+		ExpectedSecret string
+		Negative       bool
+	}{
+		{"Given inline credentials", credentialSettings{key: "k", secret: "s", credentialsFile: file}, "k", "s", false},
+		{"Given the default profile", credentialSettings{credentialsFile: file}, "default-key", "default-secret", false},
+		{"Given a named profile", credentialSettings{profile: "reseller", credentialsFile: file}, "reseller-key", "reseller-secret", false},
+		{"Given a profile with a credential_process", credentialSettings{profile: "process", credentialsFile: file}, "process-key", "process-secret", false},
+		{"Given a credential_process", credentialSettings{credentialProcess: `echo '{"key":"k","secret":"s"}'`, credentialsFile: file}, "k", "s", false},
+		{"Given no credentials file", credentialSettings{credentialsFile: missing}, "", "", false},
+		{"Given an unknown profile", credentialSettings{profile: "nope", credentialsFile: file}, "", "", true},
+		{"Given a named profile and no file", credentialSettings{profile: "reseller", credentialsFile: missing}, "", "", true},
+		{"Given a failing credential_process", credentialSettings{credentialProcess: "exit 1"}, "", "", true},
+		{"Given a credential_process printing garbage", credentialSettings{credentialProcess: "echo nope"}, "", "", true},
+	}
+	for _, test := range criteria {
+		t.Run(test.Name, func(t *testing.T) {
+			creds, err := resolveCredentials(context.Background(), test.Settings)
+			if test.Negative {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.ExpectedKey, creds.key)
+			assert.Equal(t, test.ExpectedSecret, creds.secret)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+
+	"github.com/CruGlobal/terraform-provider-godaddy/internal/api"
+)
+
+// fieldStep is one segment of a GoDaddy field path such as
+// "contactRegistrant.phone" or "records[2].data". index is -1 when the
+// segment has no subscript.
+type fieldStep struct {
+	name  string
+	index int
+}
+
+// fieldPathMapper translates a parsed GoDaddy field path into the attribute
+// path of the configuration that produced it, if it can.
+type fieldPathMapper func(steps []fieldStep) (path.Path, bool)
+
+// parseFieldPath splits a GoDaddy field path into its segments. A leading
+// "body" segment, which GoDaddy uses to refer to the payload itself, is
+// dropped, keeping its subscript for array payloads.
+func parseFieldPath(p string) []fieldStep {
+	var steps []fieldStep
+	for _, part := range strings.Split(p, ".") {
+		if part == "" {
+			continue
+		}
+		for part != "" {
+			open := strings.Index(part, "[")
+			if open < 0 {
+				steps = append(steps, fieldStep{name: part, index: -1})
+				break
+			}
+			end := strings.Index(part[open:], "]")
+			if end < 0 {
+				steps = append(steps, fieldStep{name: part, index: -1})
+				break
+			}
+			index, err := strconv.Atoi(part[open+1 : open+end])
+			if err != nil {
+				index = -1
+			}
+			steps = append(steps, fieldStep{name: part[:open], index: index})
+			part = part[open+end+1:]
+		}
+	}
+	if len(steps) > 0 && steps[0].name == "body" {
+		if steps[0].index < 0 {
+			return steps[1:]
+		}
+		steps[0].name = ""
+	}
+	return steps
+}
+
+// addAPIError records err on diags. When err carries GoDaddy field errors
+// that mapper can place, each becomes an attribute error pointing at the
+// offending configuration; anything left over is reported as a plain error.
+func addAPIError(diags *diag.Diagnostics, summary string, err error, mapper fieldPathMapper) {
+	var apiErr *api.Error
+	if mapper == nil || !errors.As(err, &apiErr) || len(apiErr.Fields) == 0 {
+		diags.AddError(summary, err.Error())
+		return
+	}
+
+	unmapped := false
+	for _, field := range apiErr.Fields {
+		p, ok := mapper(parseFieldPath(field.Path))
+		if !ok {
+			unmapped = true
+			continue
+		}
+		diags.AddAttributeError(p, summary, fmt.Sprintf("%s: %s [%s]", apiErr.Message, field.Message, field.Code))
+	}
+	if unmapped {
+		diags.AddError(summary, err.Error())
+	}
+}
+
+// addRollbackResult explains what a failed record update left behind once
+// the writes it had already made were reverted.
+func addRollbackResult(diags *diag.Diagnostics, err *api.RecordRollbackError) {
+	if len(err.LeftChanged) > 0 {
+		detail := fmt.Sprintf("These records could not be restored after the update failed, and may match neither the previous state nor the configuration: %s. Rollback error: %s",
+			strings.Join(err.LeftChanged, ", "), err.RollbackErr)
+		if len(err.RolledBack) > 0 {
+			detail += fmt.Sprintf("\n\nThese records were restored: %s.", strings.Join(err.RolledBack, ", "))
+		}
+		diags.AddError("Records left partially updated", detail)
+		return
+	}
+	diags.AddWarning(
+		"Record changes rolled back",
+		fmt.Sprintf("The update failed, so the records already written were restored to their previous values: %s.", strings.Join(err.RolledBack, ", ")),
+	)
+}
@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CruGlobal/terraform-provider-godaddy/internal/api"
+)
+
+func TestParseFieldPath(t *testing.T) {
+	assert.Equal(t, []fieldStep{{"contactRegistrant", -1}, {"phone", -1}}, parseFieldPath("contactRegistrant.phone"))
+	assert.Equal(t, []fieldStep{{"records", 2}, {"data", -1}}, parseFieldPath("records[2].data"))
+	assert.Equal(t, []fieldStep{{"", 0}, {"ttl", -1}}, parseFieldPath("body[0].ttl"))
+	assert.Equal(t, []fieldStep{{"phone", -1}}, parseFieldPath("body.phone"))
+	assert.Equal(t, []fieldStep{{"nameServers", 1}}, parseFieldPath("nameServers[1]"))
+}
+
+func TestPurchaseFieldPath(t *testing.T) {
+	var criteria = []struct {
+		Field    string
+		Expected path.Path
+	}{
+		{"contactRegistrant.phone", path.Root("registrant").AtName("phone")},
+		{"contactAdmin.addressMailing.postalCode", path.Root("admin").AtName("address").AtName("postal_code")},
+		{"contactTech.unknownField", path.Root("tech")},
+		{"period", path.Root("years_leased")},
+		{"nameServers[1]", path.Root("nameservers").AtListIndex(1)},
+	}
+	for _, test := range criteria {
+		t.Run(test.Field, func(t *testing.T) {
+			p, ok := purchaseFieldPath(parseFieldPath(test.Field))
+			assert.True(t, ok)
+			assert.True(t, test.Expected.Equal(p), "got %s", p)
+		})
+	}
+
+	_, ok := purchaseFieldPath(parseFieldPath("consent.agreedAt"))
+	assert.False(t, ok)
+}
+
+func TestAddAPIErrorForRecords(t *testing.T) {
+	ctx := context.Background()
+	elem, d := types.ObjectValue(recordObjectType().AttrTypes, map[string]attr.Value{
+		"name": types.StringValue("@"),
+		"type": types.StringValue(api.MXType),
+		"ttl":  types.Int64Value(api.DefaultTTL),
+		"data": types.StringNull(),
+		"mx": types.ObjectValueMust(mxObjectType().AttrTypes, map[string]attr.Value{
+			"priority": types.Int64Value(10),
+			"exchange": types.StringValue("mail.example.com"),
+		}),
+		"srv": types.ObjectNull(srvObjectType().AttrTypes),
+		"caa": types.ObjectNull(caaObjectType().AttrTypes),
+	})
+	assert.False(t, d.HasError())
+	set, d := types.SetValue(recordObjectType(), []attr.Value{elem})
+	assert.False(t, d.HasError())
+
+	plan := &domainRecordResourceModel{
+		Record:      set,
+		Addresses:   types.ListValueMust(types.StringType, []attr.Value{types.StringValue("192.0.2.1")}),
+		Nameservers: types.ListNull(types.StringType),
+	}
+	records, sources, d := buildRecords(ctx, plan)
+	assert.False(t, d.HasError())
+
+	var mx []*api.DomainRecord
+	for _, rec := range records {
+		if rec.Type == api.MXType {
+			mx = append(mx, rec)
+		}
+	}
+	err := fmt.Errorf("wrapped: %w", &api.RecordWriteError{
+		Type:    api.MXType,
+		Records: mx,
+		Err: &api.Error{
+			StatusCode: http.StatusUnprocessableEntity,
+			Code:       "INVALID_BODY",
+			Message:    "Request body doesn't fulfill schema",
+			Fields:     []api.FieldError{{Code: "MISMATCH_FORMAT", Message: "bad host", Path: "records[0].data"}},
+		},
+	})
+
+	var diags diag.Diagnostics
+	addAPIError(&diags, "Failed to update records", err, sources.fieldPath(err))
+	assert.Len(t, diags, 1)
+	withPath, ok := diags[0].(diag.DiagnosticWithPath)
+	assert.True(t, ok)
+	assert.True(t, path.Root("record").AtSetValue(elem).AtName("mx").AtName("exchange").Equal(withPath.Path()))
+}
+
+func TestRecordSourcesFieldPath(t *testing.T) {
+	rec := &api.DomainRecord{Type: api.MXType, Name: "@", Data: "mail.example.com", Priority: 10, TTL: api.DefaultTTL}
+	elem := path.Root("values").AtListIndex(0)
+	var criteria = []struct {
+		Name     string
+		Source   recordSource
+		Field    string
+		Expected path.Path
+	}{
+		{"Given a record set element", recordSource{path: elem, nested: true}, "ttl", elem.AtName("ttl")},
+		{"Given a values element", recordSource{path: elem, nested: true, root: true}, "ttl", path.Root("ttl")},
+		{"Given a values element's data", recordSource{path: elem, nested: true, root: true}, "data", elem.AtName("mx").AtName("exchange")},
+		{"Given a flat attribute", recordSource{path: path.Root("addresses").AtListIndex(0)}, "data", path.Root("addresses").AtListIndex(0)},
+	}
+	for _, test := range criteria {
+		t.Run(test.Name, func(t *testing.T) {
+			err := &api.RecordWriteError{Type: api.MXType, Records: []*api.DomainRecord{rec}}
+			p, ok := recordSources{rec: test.Source}.fieldPath(err)(parseFieldPath("records[0]." + test.Field))
+			assert.True(t, ok)
+			assert.True(t, test.Expected.Equal(p), "got %s", p)
+		})
+	}
+}
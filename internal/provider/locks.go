@@ -0,0 +1,286 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/CruGlobal/terraform-provider-godaddy/internal/api"
+)
+
+// coalesceWindow is how long the first record update for a domain waits for
+// others to join it when coalesce_record_writes is enabled.
+const coalesceWindow = 500 * time.Millisecond
+
+// domainLocks serializes changes to each domain across every resource and
+// provider configuration in the process. Terraform applies resources in
+// parallel, and GoDaddy's record and nameserver updates are read-modify-write.
+var domainLocks = newKeyedLock()
+
+// keyedLock is a set of mutexes created on demand for each key and discarded
+// once nobody holds or waits for them. Waiting for a lock can be cancelled.
+type keyedLock struct {
+	mu    sync.Mutex
+	locks map[string]*refLock
+}
+
+type refLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+func newKeyedLock() *keyedLock {
+	return &keyedLock{locks: map[string]*refLock{}}
+}
+
+// lock waits until key is free or ctx is cancelled, and returns the function
+// that frees it again.
+func (k *keyedLock) lock(ctx context.Context, key string) (func(), error) {
+	key = strings.ToLower(key)
+
+	k.mu.Lock()
+	l, ok := k.locks[key]
+	if !ok {
+		l = &refLock{ch: make(chan struct{}, 1)}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+
+	select {
+	case l.ch <- struct{}{}:
+		return func() {
+			<-l.ch
+			k.release(key, l)
+		}, nil
+	case <-ctx.Done():
+		k.release(key, l)
+		return nil, ctx.Err()
+	}
+}
+
+func (k *keyedLock) release(key string, l *refLock) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	l.refs--
+	if l.refs == 0 {
+		delete(k.locks, key)
+	}
+}
+
+// lockDomain waits for exclusive use of domain. Every resource that changes
+// a domain's records or settings takes it first.
+func lockDomain(ctx context.Context, domain string) (func(), error) {
+	tflog.Debug(ctx, "waiting for domain lock", map[string]any{"domain": domain})
+	unlock, err := domainLocks.lock(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	tflog.Debug(ctx, "acquired domain lock", map[string]any{"domain": domain})
+	return unlock, nil
+}
+
+// recordUpdate is one resource's desired records for a domain, limited to
+// the records managed selects; a nil filter manages the whole zone. check,
+// if set, is run on the domain's records under its lock, and an error leaves
+// the update out.
+type recordUpdate struct {
+	desired []*api.DomainRecord
+	managed api.RecordFilter
+	check   func(records []*api.DomainRecord) error
+}
+
+// verify runs the update's check on records, if it has one.
+func (u recordUpdate) verify(records []*api.DomainRecord) error {
+	if u.check == nil {
+		return nil
+	}
+	return u.check(records)
+}
+
+// sync applies the update on its own. The caller holds the domain's lock.
+func (u recordUpdate) sync(ctx context.Context, client *api.Client, customer, domain string) (api.RecordChangeSet, error) {
+	var checkErr error
+	changes, err := client.SyncDomainRecordsFunc(ctx, customer, domain, func(current []*api.DomainRecord) api.RecordChangeSet {
+		if checkErr = u.verify(current); checkErr != nil {
+			return nil
+		}
+		return api.DiffRecords(current, u.desired, u.managed)
+	})
+	if checkErr != nil {
+		return nil, checkErr
+	}
+	return changes, err
+}
+
+// apply returns records with the update's managed records replaced by its
+// desired ones.
+func (u recordUpdate) apply(records []*api.DomainRecord) []*api.DomainRecord {
+	out := make([]*api.DomainRecord, 0, len(records)+len(u.desired))
+	if u.managed != nil {
+		for _, rec := range records {
+			if !u.managed(rec) {
+				out = append(out, rec)
+			}
+		}
+	}
+	return append(out, u.desired...)
+}
+
+// recordBatch collects the updates for one domain made within a
+// coalesceWindow. Once closed, its updates are being applied and can no
+// longer be withdrawn.
+type recordBatch struct {
+	entries []*batchEntry
+	closed  bool
+	done    chan struct{}
+}
+
+// batchEntry is one update in a batch and, once the batch is done, its
+// outcome.
+type batchEntry struct {
+	update recordUpdate
+	result recordResult
+}
+
+type recordResult struct {
+	changes api.RecordChangeSet
+	err     error
+}
+
+// finish gives every update in the batch the same outcome, except those
+// whose check failed, which get its error.
+func (b *recordBatch) finish(changes api.RecordChangeSet, err error, checkErrs []error) {
+	for i, e := range b.entries {
+		e.result = recordResult{changes: changes, err: err}
+		if checkErrs != nil && checkErrs[i] != nil {
+			e.result = recordResult{err: checkErrs[i]}
+		}
+	}
+}
+
+// recordCoalescer merges concurrent record updates for a domain into a single
+// read and a single set of writes.
+type recordCoalescer struct {
+	mu      sync.Mutex
+	pending map[string]*recordBatch
+}
+
+func newRecordCoalescer() *recordCoalescer {
+	return &recordCoalescer{pending: map[string]*recordBatch{}}
+}
+
+// sync adds update to the domain's open batch, opening one if there is none,
+// and waits for the batch to be applied. A caller cancelled while the batch
+// is still open withdraws its update; once the batch is closed its records
+// are already being written, so it waits to report what happened to them.
+func (c *recordCoalescer) sync(ctx context.Context, client *api.Client, customer, domain string, update recordUpdate) (api.RecordChangeSet, error) {
+	key := customer + "/" + strings.ToLower(domain)
+	entry := &batchEntry{update: update}
+
+	c.mu.Lock()
+	b, joined := c.pending[key]
+	if joined {
+		tflog.Debug(ctx, "joined pending record update", map[string]any{"domain": domain})
+	} else {
+		b = &recordBatch{done: make(chan struct{})}
+		c.pending[key] = b
+		// The batch runs on a context of its own, so that cancelling the
+		// caller that opened it neither fails nor rolls back the others.
+		go c.run(context.WithoutCancel(ctx), client, customer, domain, key, b)
+	}
+	b.entries = append(b.entries, entry)
+	c.mu.Unlock()
+
+	select {
+	case <-b.done:
+		return entry.result.changes, entry.result.err
+	case <-ctx.Done():
+	}
+
+	c.mu.Lock()
+	if !b.closed {
+		b.entries = slices.DeleteFunc(b.entries, func(e *batchEntry) bool { return e == entry })
+		c.mu.Unlock()
+		return nil, ctx.Err()
+	}
+	c.mu.Unlock()
+	<-b.done
+	return entry.result.changes, entry.result.err
+}
+
+// run waits out the window, closes the batch and applies whatever updates
+// are left in it.
+func (c *recordCoalescer) run(ctx context.Context, client *api.Client, customer, domain, key string, b *recordBatch) {
+	defer close(b.done)
+	time.Sleep(coalesceWindow)
+
+	c.mu.Lock()
+	delete(c.pending, key)
+	b.closed = true
+	c.mu.Unlock()
+	if len(b.entries) == 0 {
+		return
+	}
+
+	unlock, err := lockDomain(ctx, domain)
+	if err != nil {
+		b.finish(nil, err, nil)
+		return
+	}
+	defer unlock()
+	c.apply(ctx, client, customer, domain, b)
+}
+
+// apply writes a closed batch. When GoDaddy rejects a write, the updates are
+// applied again one at a time, so that each resource gets its own outcome
+// rather than failing for another's invalid record.
+func (c *recordCoalescer) apply(ctx context.Context, client *api.Client, customer, domain string, b *recordBatch) {
+	tflog.Info(ctx, "applying coalesced record updates", map[string]any{"domain": domain, "updates": len(b.entries)})
+	checkErrs := make([]error, len(b.entries))
+	changes, err := client.SyncDomainRecordsFunc(ctx, customer, domain, func(current []*api.DomainRecord) api.RecordChangeSet {
+		desired := current
+		for i, e := range b.entries {
+			u := e.update
+			// Checking the records as the earlier updates leave them stops two
+			// updates in a batch from both passing.
+			if checkErrs[i] = u.verify(desired); checkErrs[i] == nil {
+				desired = u.apply(desired)
+			}
+		}
+		return api.DiffRecords(current, desired, nil)
+	})
+
+	if err == nil || len(b.entries) == 1 || !rejected(err) {
+		b.finish(changes, err, checkErrs)
+		return
+	}
+
+	tflog.Warn(ctx, "coalesced record update failed, applying updates one at a time", map[string]any{"domain": domain, "error": err.Error()})
+	for i, e := range b.entries {
+		if checkErrs[i] != nil {
+			e.result = recordResult{err: checkErrs[i]}
+			continue
+		}
+		changes, err := e.update.sync(ctx, client, customer, domain)
+		e.result = recordResult{changes: changes, err: err}
+	}
+}
+
+// rejected reports whether err is GoDaddy turning a write down. Outages,
+// rate limiting and network failures aren't, since applying each update
+// again would only repeat them.
+func rejected(err error) bool {
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode >= http.StatusBadRequest && apiErr.StatusCode < http.StatusInternalServerError &&
+		apiErr.StatusCode != http.StatusTooManyRequests
+}
@@ -0,0 +1,214 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CruGlobal/terraform-provider-godaddy/internal/api"
+)
+
+func TestKeyedLock(t *testing.T) {
+	locks := newKeyedLock()
+	unlock, err := locks.lock(context.Background(), "example.com")
+	assert.Nil(t, err)
+
+	// Other domains aren't blocked, but the same one is, whatever its case.
+	other, err := locks.lock(context.Background(), "example.org")
+	assert.Nil(t, err)
+	other()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = locks.lock(ctx, "EXAMPLE.com")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	unlock()
+	unlock, err = locks.lock(context.Background(), "example.com")
+	assert.Nil(t, err)
+	unlock()
+	assert.Empty(t, locks.locks)
+}
+
+func TestRecordCoalescerMergesConcurrentUpdates(t *testing.T) {
+	var mu sync.Mutex
+	var reads int
+	var writes []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodGet {
+			reads++
+			json.NewEncoder(w).Encode([]*api.DomainRecord{{Type: api.AType, Name: "@", Data: "192.0.2.1", TTL: api.DefaultTTL}})
+			return
+		}
+		writes = append(writes, r.Method+" "+r.URL.Path)
+	}))
+	defer srv.Close()
+
+	client, err := api.NewClient(srv.URL, "coalesce-key", "secret", api.WithRateLimit(6000, false))
+	assert.Nil(t, err)
+	data := &providerData{client: client, coalescer: newRecordCoalescer()}
+
+	ofType := func(t string) api.RecordFilter {
+		return func(r *api.DomainRecord) bool { return r.Type == t }
+	}
+	updates := []recordUpdate{
+		{desired: []*api.DomainRecord{{Type: api.TXTType, Name: "@", Data: "v=spf1 -all", TTL: api.DefaultTTL}}, managed: ofType(api.TXTType)},
+		{desired: []*api.DomainRecord{{Type: api.MXType, Name: "@", Data: "mx.example.com", TTL: api.DefaultTTL}}, managed: ofType(api.MXType)},
+	}
+
+	var wg sync.WaitGroup
+	results := make([]api.RecordChangeSet, len(updates))
+	for i, u := range updates {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			changes, err := data.syncRecords(context.Background(), "", "example.com", u)
+			assert.Nil(t, err)
+			results[i] = changes
+		}()
+	}
+	wg.Wait()
+
+	sort.Strings(writes)
+	assert.Equal(t, 1, reads)
+	assert.Equal(t, []string{"PUT /v1/domains/example.com/records/MX/@", "PUT /v1/domains/example.com/records/TXT/@"}, writes)
+	assert.Len(t, results[0], 2)
+	assert.Len(t, results[1], 2)
+}
+
+func TestRecordCoalescerSeparatesFailedUpdates(t *testing.T) {
+	zone := &fakeZone{domain: "example.com"}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// GoDaddy rejects the TXT record, whether written alone or with others.
+		if r.Method == http.MethodPut && strings.Contains(r.URL.Path, "/records/TXT") {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]string{"code": "INVALID_BODY", "message": "bad TXT record"})
+			return
+		}
+		zone.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	client, err := api.NewClient(srv.URL, "coalesce-failure-key", "secret", api.WithRateLimit(6000, false))
+	assert.Nil(t, err)
+	data := &providerData{client: client, coalescer: newRecordCoalescer()}
+
+	ofType := func(t string) api.RecordFilter {
+		return func(r *api.DomainRecord) bool { return r.Type == t }
+	}
+	updates := []recordUpdate{
+		{desired: []*api.DomainRecord{{Type: api.TXTType, Name: "@", Data: "v=spf1 -all", TTL: api.DefaultTTL}}, managed: ofType(api.TXTType)},
+		{desired: []*api.DomainRecord{{Type: api.MXType, Name: "@", Data: "mx.example.com", TTL: api.DefaultTTL}}, managed: ofType(api.MXType)},
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(updates))
+	for i, u := range updates {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = data.syncRecords(context.Background(), "", "example.com", u)
+		}()
+	}
+	wg.Wait()
+
+	// Only the update with the rejected record fails, and its error names
+	// its own records.
+	var writeErr *api.RecordWriteError
+	assert.ErrorAs(t, errs[0], &writeErr)
+	assert.Equal(t, api.TXTType, writeErr.Type)
+	assert.Nil(t, errs[1])
+	assert.Equal(t, []string{"MX @ mx.example.com"}, zone.ofType(api.MXType))
+	assert.Empty(t, zone.ofType(api.TXTType))
+}
+
+func TestRecordCoalescerWithdrawsCancelledUpdates(t *testing.T) {
+	zone, client := newFakeZone(t, "example.com")
+	data := &providerData{client: client, coalescer: newRecordCoalescer()}
+
+	ofType := func(t string) api.RecordFilter {
+		return func(r *api.DomainRecord) bool { return r.Type == t }
+	}
+	txt := recordUpdate{desired: []*api.DomainRecord{{Type: api.TXTType, Name: "@", Data: "v=spf1 -all", TTL: api.DefaultTTL}}, managed: ofType(api.TXTType)}
+	mx := recordUpdate{desired: []*api.DomainRecord{{Type: api.MXType, Name: "@", Data: "mx.example.com", TTL: api.DefaultTTL}}, managed: ofType(api.MXType)}
+
+	// The caller that opens the batch is cancelled before it closes; the
+	// caller that joined it still gets its records written.
+	ctx, cancel := context.WithCancel(context.Background())
+	opened := make(chan error, 1)
+	go func() {
+		_, err := data.syncRecords(ctx, "", "example.com", txt)
+		opened <- err
+	}()
+	time.Sleep(coalesceWindow / 5)
+	joined := make(chan error, 1)
+	go func() {
+		_, err := data.syncRecords(context.Background(), "", "example.com", mx)
+		joined <- err
+	}()
+	time.Sleep(coalesceWindow / 5)
+	cancel()
+
+	assert.ErrorIs(t, <-opened, context.Canceled)
+	assert.Nil(t, <-joined)
+	assert.Empty(t, zone.ofType(api.TXTType))
+	assert.Equal(t, []string{"MX @ mx.example.com"}, zone.ofType(api.MXType))
+}
+
+func TestRecordCoalescerKeepsServerErrorsTogether(t *testing.T) {
+	zone := &fakeZone{domain: "example.com"}
+	var mu sync.Mutex
+	reads := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/records") {
+			mu.Lock()
+			reads++
+			mu.Unlock()
+		}
+		// An outage isn't a rejection, so it isn't retried update by update.
+		if r.Method == http.MethodPut {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]string{"code": "UNAVAILABLE", "message": "try again later"})
+			return
+		}
+		zone.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	client, err := api.NewClient(srv.URL, "coalesce-outage-key", "secret", api.WithRateLimit(6000, false), api.WithRetryPolicy(api.RetryPolicy{}))
+	assert.Nil(t, err)
+	data := &providerData{client: client, coalescer: newRecordCoalescer()}
+
+	ofType := func(t string) api.RecordFilter {
+		return func(r *api.DomainRecord) bool { return r.Type == t }
+	}
+	updates := []recordUpdate{
+		{desired: []*api.DomainRecord{{Type: api.TXTType, Name: "@", Data: "v=spf1 -all", TTL: api.DefaultTTL}}, managed: ofType(api.TXTType)},
+		{desired: []*api.DomainRecord{{Type: api.MXType, Name: "@", Data: "mx.example.com", TTL: api.DefaultTTL}}, managed: ofType(api.MXType)},
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(updates))
+	for i, u := range updates {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = data.syncRecords(context.Background(), "", "example.com", u)
+		}()
+	}
+	wg.Wait()
+
+	assert.Error(t, errs[0])
+	assert.Equal(t, errs[0], errs[1])
+	assert.Equal(t, 1, reads)
+}
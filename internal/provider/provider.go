@@ -2,14 +2,21 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 
 	"github.com/CruGlobal/terraform-provider-godaddy/internal/api"
 )
@@ -21,9 +28,28 @@ type godaddyProvider struct {
 }
 
 type godaddyProviderModel struct {
-	Key     types.String `tfsdk:"key"`
-	Secret  types.String `tfsdk:"secret"`
-	BaseURL types.String `tfsdk:"baseurl"`
+	Key                   types.String `tfsdk:"key"`
+	Secret                types.String `tfsdk:"secret"`
+	Profile               types.String `tfsdk:"profile"`
+	SharedCredentialsFile types.String `tfsdk:"shared_credentials_file"`
+	CredentialProcess     types.String `tfsdk:"credential_process"`
+
+	SkipCredentialsValidation types.Bool `tfsdk:"skip_credentials_validation"`
+	CoalesceRecordWrites      types.Bool `tfsdk:"coalesce_record_writes"`
+
+	Customer types.String `tfsdk:"customer"`
+
+	BaseURL     types.String `tfsdk:"baseurl"`
+	Environment types.String `tfsdk:"environment"`
+	MaxRetries  types.Int64  `tfsdk:"max_retries"`
+	MaxBackoff  types.Int64  `tfsdk:"max_backoff"`
+
+	RequestsPerMinute types.Int64 `tfsdk:"requests_per_minute"`
+	AdaptiveRateLimit types.Bool  `tfsdk:"adaptive_rate_limit"`
+
+	ProxyURL           types.String `tfsdk:"proxy_url"`
+	CABundleFile       types.String `tfsdk:"ca_bundle_file"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
 }
 
 func New(version string) func() provider.Provider {
@@ -51,8 +77,64 @@ func (p *godaddyProvider) Schema(_ context.Context, _ provider.SchemaRequest, re
 				Optional:    true,
 				Sensitive:   true,
 			},
+			"profile": schema.StringAttribute{
+				Description: "Name of the profile in the shared credentials file to read `key` and `secret` from. May also be set with the `GODADDY_PROFILE` environment variable. Defaults to `default`.",
+				Optional:    true,
+			},
+			"shared_credentials_file": schema.StringAttribute{
+				Description: "Path to the shared credentials file. May also be set with the `GODADDY_SHARED_CREDENTIALS_FILE` environment variable. Defaults to `~/.godaddy/credentials`.",
+				Optional:    true,
+			},
+			"credential_process": schema.StringAttribute{
+				Description: "Command to run to obtain credentials. It must print a JSON object with `key` and `secret` fields to stdout. Takes precedence over the shared credentials file.",
+				Optional:    true,
+			},
+			"customer": schema.StringAttribute{
+				Description: "Default GoDaddy customer (shopper) ID, sent as `X-Shopper-Id`, for resources and data sources that don't set their own `customer`. May also be set with the `GODADDY_CUSTOMER_ID` environment variable.",
+				Optional:    true,
+			},
 			"baseurl": schema.StringAttribute{
-				Description: "GoDaddy API base URL. Defaults to `https://api.godaddy.com`.",
+				Description: "GoDaddy API base URL. Defaults to the URL of the selected `environment`. May also be set with the `GODADDY_API_URL` environment variable.",
+				Optional:    true,
+			},
+			"environment": schema.StringAttribute{
+				Description: "GoDaddy environment to use: `production` (`https://api.godaddy.com`) or `ote`, GoDaddy's test environment (`https://api.ote-godaddy.com`). OTE requires its own API key. May also be set with the `GODADDY_ENVIRONMENT` environment variable. Defaults to `production`, or to the environment `baseurl` points at.",
+				Optional:    true,
+			},
+			"max_retries": schema.Int64Attribute{
+				Description: "Maximum number of times a throttled (429) or failed (5xx) API request is retried. Non-idempotent requests such as domain purchases are only retried on 429. Defaults to `3`; set to `0` to disable retries.",
+				Optional:    true,
+			},
+			"max_backoff": schema.Int64Attribute{
+				Description: "Maximum delay, in seconds, between retries of an API request. A longer delay requested by GoDaddy through `Retry-After` is still honored. Defaults to `30`.",
+				Optional:    true,
+			},
+			"requests_per_minute": schema.Int64Attribute{
+				Description: "Maximum number of API requests per minute. The limit is shared by every provider configuration using the same API key; if they disagree, the lowest value applies. Defaults to `60`, GoDaddy's documented per-endpoint limit.",
+				Optional:    true,
+			},
+			"adaptive_rate_limit": schema.BoolAttribute{
+				Description: "Slow down further whenever GoDaddy responds with 429 Too Many Requests, and recover gradually as requests succeed. Defaults to `false`.",
+				Optional:    true,
+			},
+			"proxy_url": schema.StringAttribute{
+				Description: "URL of an HTTP(S) proxy to send API requests through, e.g. `http://proxy.internal:3128`. When unset, the standard `HTTPS_PROXY`, `HTTP_PROXY` and `NO_PROXY` environment variables are honored.",
+				Optional:    true,
+			},
+			"ca_bundle_file": schema.StringAttribute{
+				Description: "Path to a PEM file of additional CA certificates to trust, e.g. for a TLS-intercepting proxy or an internal API gateway. The system roots are still trusted.",
+				Optional:    true,
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				Description: "Skip verification of the API server's TLS certificate. Only use this for testing. Defaults to `false`.",
+				Optional:    true,
+			},
+			"coalesce_record_writes": schema.BoolAttribute{
+				Description: "Merge record changes that different resources make to the same domain at the same time into a single set of API writes. The first change to a domain waits briefly for others to join it. Defaults to `false`.",
+				Optional:    true,
+			},
+			"skip_credentials_validation": schema.BoolAttribute{
+				Description: "Skip the API request the provider makes on startup to check that the credentials are valid and allowed to use the GoDaddy API, e.g. for offline plans. Defaults to `false`.",
 				Optional:    true,
 			},
 		},
@@ -66,37 +148,123 @@ func (p *godaddyProvider) Configure(ctx context.Context, req provider.ConfigureR
 		return
 	}
 
-	key := stringValueOrEnv(cfg.Key, "GODADDY_API_KEY", "")
-	secret := stringValueOrEnv(cfg.Secret, "GODADDY_API_SECRET", "")
-	baseURL := stringValueOrEnv(cfg.BaseURL, "GODADDY_API_URL", "https://api.godaddy.com")
+	baseURL, environment, d := resolveEndpoint(cfg)
+	resp.Diagnostics.Append(d...)
+
+	creds, err := resolveCredentials(ctx, credentialSettings{
+		key:               stringValueOrEnv(cfg.Key, "GODADDY_API_KEY", ""),
+		secret:            stringValueOrEnv(cfg.Secret, "GODADDY_API_SECRET", ""),
+		profile:           stringValueOrEnv(cfg.Profile, "GODADDY_PROFILE", ""),
+		credentialsFile:   stringValueOrEnv(cfg.SharedCredentialsFile, "GODADDY_SHARED_CREDENTIALS_FILE", defaultCredentialsFile()),
+		credentialProcess: cfg.CredentialProcess.ValueString(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to load GoDaddy credentials", err.Error())
+		return
+	}
+	key, secret := creds.key, creds.secret
 
 	if key == "" {
 		resp.Diagnostics.AddError(
 			"Missing GoDaddy API key",
-			"Set the `key` provider attribute or the `GODADDY_API_KEY` environment variable.",
+			"Set the `key` provider attribute or the `GODADDY_API_KEY` environment variable, or configure a `credential_process` or a profile in the shared credentials file.",
 		)
 	}
 	if secret == "" {
 		resp.Diagnostics.AddError(
 			"Missing GoDaddy API secret",
-			"Set the `secret` provider attribute or the `GODADDY_API_SECRET` environment variable.",
+			"Set the `secret` provider attribute or the `GODADDY_API_SECRET` environment variable, or configure a `credential_process` or a profile in the shared credentials file.",
 		)
 	}
+
+	retry := api.DefaultRetryPolicy
+	if !cfg.MaxRetries.IsNull() && !cfg.MaxRetries.IsUnknown() {
+		if cfg.MaxRetries.ValueInt64() < 0 {
+			resp.Diagnostics.AddAttributeError(path.Root("max_retries"), "Invalid max_retries", "`max_retries` must not be negative.")
+		}
+		retry.MaxRetries = int(cfg.MaxRetries.ValueInt64())
+	}
+	if !cfg.MaxBackoff.IsNull() && !cfg.MaxBackoff.IsUnknown() {
+		if cfg.MaxBackoff.ValueInt64() < 1 {
+			resp.Diagnostics.AddAttributeError(path.Root("max_backoff"), "Invalid max_backoff", "`max_backoff` must be at least 1 second.")
+		}
+		retry.MaxBackoff = time.Duration(cfg.MaxBackoff.ValueInt64()) * time.Second
+	}
+	requestsPerMinute := api.DefaultRequestsPerMinute
+	if !cfg.RequestsPerMinute.IsNull() && !cfg.RequestsPerMinute.IsUnknown() {
+		if cfg.RequestsPerMinute.ValueInt64() < 1 {
+			resp.Diagnostics.AddAttributeError(path.Root("requests_per_minute"), "Invalid requests_per_minute", "`requests_per_minute` must be at least 1.")
+		}
+		requestsPerMinute = int(cfg.RequestsPerMinute.ValueInt64())
+	}
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	client, err := api.NewClient(baseURL, key, secret)
+	opts := []api.ClientOpt{
+		api.WithEnvironment(environment),
+		api.WithUserAgent(p.userAgent(req.TerraformVersion)),
+		api.WithRetryPolicy(retry),
+		api.WithRateLimit(requestsPerMinute, cfg.AdaptiveRateLimit.ValueBool()),
+	}
+	if proxyURL := cfg.ProxyURL.ValueString(); proxyURL != "" {
+		opts = append(opts, api.WithProxyURL(proxyURL))
+	}
+	if caFile := cfg.CABundleFile.ValueString(); caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("ca_bundle_file"), "Unable to read CA bundle", err.Error())
+			return
+		}
+		opts = append(opts, api.WithCACertificates(pem))
+	}
+	if cfg.InsecureSkipVerify.ValueBool() {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("insecure_skip_verify"),
+			"TLS certificate verification is disabled",
+			"The GoDaddy API server's certificate will not be verified. Do not use `insecure_skip_verify` outside of testing.",
+		)
+		opts = append(opts, api.WithInsecureSkipVerify(true))
+	}
+
+	client, err := api.NewClient(baseURL, key, secret, opts...)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to configure GoDaddy client",
-			fmt.Sprintf("Error creating GoDaddy API client: %s", err),
+			fmt.Sprintf("Error creating GoDaddy API client for the %s environment (%s): %s", environment, baseURL, err),
 		)
 		return
 	}
+	tflog.Info(ctx, "configured GoDaddy client", map[string]any{
+		"environment":        environment,
+		"base_url":           baseURL,
+		"credentials_source": creds.source,
+	})
+
+	switch {
+	case cfg.SkipCredentialsValidation.ValueBool():
+		tflog.Debug(ctx, "skipping GoDaddy credentials validation")
+	case cfg.Key.IsUnknown() || cfg.Secret.IsUnknown() || cfg.CredentialProcess.IsUnknown():
+		// Credentials computed from other resources aren't known until apply.
+		tflog.Debug(ctx, "skipping GoDaddy credentials validation of unknown credentials")
+	default:
+		resp.Diagnostics.Append(verifyCredentials(ctx, client, creds.source)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	data := &providerData{
+		client:   client,
+		customer: stringValueOrEnv(cfg.Customer, "GODADDY_CUSTOMER_ID", ""),
 
-	resp.DataSourceData = client
-	resp.ResourceData = client
+		customerUnknown: cfg.Customer.IsUnknown(),
+	}
+	if cfg.CoalesceRecordWrites.ValueBool() {
+		data.coalescer = newRecordCoalescer()
+	}
+	resp.DataSourceData = data
+	resp.ResourceData = data
 }
 
 func (p *godaddyProvider) Resources(_ context.Context) []func() resource.Resource {
@@ -104,6 +272,7 @@ func (p *godaddyProvider) Resources(_ context.Context) []func() resource.Resourc
 		NewDomainRecordResource,
 		NewDomainPurchaseResource,
 		NewDomainNameserversResource,
+		NewDNSRecordResource,
 	}
 }
 
@@ -111,6 +280,94 @@ func (p *godaddyProvider) DataSources(_ context.Context) []func() datasource.Dat
 	return nil
 }
 
+// resolveEndpoint works out the API base URL and environment name from the
+// baseurl and environment settings, rejecting combinations where the two
+// disagree so that OTE and production can't be mixed up.
+func resolveEndpoint(cfg godaddyProviderModel) (string, string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	baseURL := stringValueOrEnv(cfg.BaseURL, "GODADDY_API_URL", "")
+	environment := strings.ToLower(stringValueOrEnv(cfg.Environment, "GODADDY_ENVIRONMENT", ""))
+
+	if environment != "" {
+		envURL, err := api.BaseURLForEnvironment(environment)
+		if err != nil {
+			diags.AddAttributeError(path.Root("environment"), "Invalid GoDaddy environment", err.Error())
+			return "", "", diags
+		}
+		if baseURL == "" {
+			return envURL, environment, diags
+		}
+		if inferred := api.EnvironmentForBaseURL(baseURL); inferred != "" && inferred != environment {
+			diags.AddAttributeError(
+				path.Root("baseurl"),
+				"Conflicting GoDaddy environment and base URL",
+				fmt.Sprintf("The %s environment was selected, but the base URL %s belongs to the %s environment. Remove `baseurl` or make it match `environment`.", environment, baseURL, inferred),
+			)
+		}
+		return baseURL, environment, diags
+	}
+
+	if baseURL == "" {
+		return api.ProductionBaseURL, api.EnvironmentProduction, diags
+	}
+	if inferred := api.EnvironmentForBaseURL(baseURL); inferred != "" {
+		return baseURL, inferred, diags
+	}
+	// A custom base URL such as an API gateway; assume it fronts production.
+	return baseURL, api.EnvironmentProduction, diags
+}
+
+// verifyCredentials makes a single authenticated request and turns the ways
+// it can fail into diagnostics that say what to do about it.
+func verifyCredentials(ctx context.Context, client *api.Client, source string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	err := client.VerifyCredentials(ctx)
+	if err == nil {
+		return diags
+	}
+
+	environment := client.Environment()
+	var apiErr *api.Error
+	switch {
+	case api.IsUnauthorized(err):
+		diags.AddError(
+			"Invalid GoDaddy API credentials",
+			fmt.Sprintf("GoDaddy rejected the API key and secret from %s for the %s environment: %s\n\n"+
+				"Check that the key and secret are correct and were issued for this environment; "+
+				"production and OTE use separate keys.", source, environment, err),
+		)
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden && apiErr.Code == "ACCESS_DENIED":
+		diags.AddError(
+			"GoDaddy API access denied",
+			fmt.Sprintf("GoDaddy accepted the API key from %s but denied it access to the %s API: %s\n\n"+
+				"GoDaddy only grants production API access to accounts that meet its API eligibility "+
+				"requirements, such as a minimum number of domains. Check the account's eligibility "+
+				"with GoDaddy, or use the `ote` environment for testing.", source, environment, err),
+		)
+	case api.IsForbidden(err):
+		diags.AddError(
+			"GoDaddy API access denied",
+			fmt.Sprintf("The API key from %s is not allowed to use the %s API: %s", source, environment, err),
+		)
+	default:
+		diags.AddError(
+			"Unable to verify GoDaddy credentials",
+			fmt.Sprintf("Checking the API credentials against the %s environment failed: %s\n\n"+
+				"Set `skip_credentials_validation` to skip this check, e.g. for offline plans.", environment, err),
+		)
+	}
+	return diags
+}
+
+// userAgent identifies the provider and the Terraform version driving it,
+// e.g. "terraform-provider-godaddy/2.1.0 (terraform/1.9.5)".
+func (p *godaddyProvider) userAgent(terraformVersion string) string {
+	if terraformVersion == "" {
+		terraformVersion = "unknown"
+	}
+	return fmt.Sprintf("terraform-provider-godaddy/%s (terraform/%s)", p.version, terraformVersion)
+}
+
 func stringValueOrEnv(v types.String, envVar, fallback string) string {
 	if !v.IsNull() && !v.IsUnknown() {
 		return v.ValueString()
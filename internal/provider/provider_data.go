@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/CruGlobal/terraform-provider-godaddy/internal/api"
+)
+
+// providerData is what Configure hands to every resource and data source.
+type providerData struct {
+	client *api.Client
+
+	// customer is the default customer (shopper) ID for resources that don't
+	// set their own.
+	customer string
+	// customerUnknown is set while planning with a provider customer that
+	// depends on values not known until apply.
+	customerUnknown bool
+
+	// coalescer merges concurrent record updates when coalesce_record_writes
+	// is enabled; nil otherwise.
+	coalescer *recordCoalescer
+}
+
+// syncRecords applies update to the domain's records, holding the domain's
+// lock so that it doesn't race other resources. With coalescing enabled,
+// concurrent calls for a domain are merged, and each caller gets back the
+// change set of the whole batch.
+func (d *providerData) syncRecords(ctx context.Context, customer, domain string, update recordUpdate) (api.RecordChangeSet, error) {
+	if d.coalescer != nil {
+		return d.coalescer.sync(ctx, d.client, customer, domain, update)
+	}
+
+	unlock, err := lockDomain(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return update.sync(ctx, d.client, customer, domain)
+}
+
+// planCustomer fills in the provider's default customer when a resource
+// doesn't configure one, so that changing the default shows up in the plan.
+// It leaves the plan alone while the provider is unconfigured or the default
+// isn't known yet, so the customer is planned as known after apply.
+func (d *providerData) planCustomer(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if d == nil || d.customerUnknown || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var configured types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("customer"), &configured)...)
+	if resp.Diagnostics.HasError() || !configured.IsNull() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("customer"), d.customerValue())...)
+}
+
+// importCustomer records the provider's default customer on an imported
+// resource, so that the first read uses it.
+func (d *providerData) importCustomer(ctx context.Context, resp *resource.ImportStateResponse) {
+	if d == nil || d.customerUnknown || d.customer == "" {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("customer"), d.customerValue())...)
+}
+
+func (d *providerData) customerValue() types.String {
+	if d.customer == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(d.customer)
+}
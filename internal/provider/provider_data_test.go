@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanCustomer(t *testing.T) {
+	s := schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"customer": schema.StringAttribute{Optional: true, Computed: true},
+		},
+	}
+	objectType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{"customer": tftypes.String}}
+	value := func(v interface{}) tftypes.Value {
+		return tftypes.NewValue(objectType, map[string]tftypes.Value{"customer": tftypes.NewValue(tftypes.String, v)})
+	}
+
+	var criteria = []struct {
+		Name       string
+		Data       *providerData
+		Configured interface{}
+		Expected   types.String
+	}{
+		{"Given a provider default", &providerData{customer: "123"}, nil, types.StringValue("123")},
+		{"Given no provider default", &providerData{}, nil, types.StringNull()},
+		{"Given a resource customer", &providerData{customer: "123"}, "456", types.StringValue("456")},
+		{"Given an unknown provider default", &providerData{customerUnknown: true}, nil, types.StringUnknown()},
+		{"Given an unconfigured provider", nil, nil, types.StringUnknown()},
+	}
+	for _, test := range criteria {
+		t.Run(test.Name, func(t *testing.T) {
+			planned := test.Configured
+			if planned == nil {
+				planned = tftypes.UnknownValue
+			}
+			req := resource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: s, Raw: value(test.Configured)},
+				Plan:   tfsdk.Plan{Schema: s, Raw: value(planned)},
+			}
+			resp := &resource.ModifyPlanResponse{Plan: req.Plan}
+
+			test.Data.planCustomer(context.Background(), req, resp)
+			assert.False(t, resp.Diagnostics.HasError())
+
+			var customer types.String
+			resp.Diagnostics.Append(resp.Plan.GetAttribute(context.Background(), path.Root("customer"), &customer)...)
+			assert.Equal(t, test.Expected, customer)
+		})
+	}
+}
@@ -1,10 +1,18 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CruGlobal/terraform-provider-godaddy/internal/api"
 )
 
 // testAccProtoV6ProviderFactories is the entrypoint for terraform-plugin-testing
@@ -20,3 +28,82 @@ func TestProvider(t *testing.T) {
 		t.Fatalf("provider server failed to construct: %s", err)
 	}
 }
+
+func TestResolveEndpoint(t *testing.T) {
+	var criteria = []struct {
+		Name        string
+		BaseURL     string
+		Environment string
+		ExpectedURL string
+		ExpectedEnv string
+		Negative    bool
+	}{
+		{"Given nothing", "", "", api.ProductionBaseURL, api.EnvironmentProduction, false},
+		{"Given the OTE environment", "", "ote", api.OTEBaseURL, api.EnvironmentOTE, false},
+		{"Given an OTE base URL", api.OTEBaseURL, "", api.OTEBaseURL, api.EnvironmentOTE, false},
+		{"Given a matching pair", api.OTEBaseURL, "OTE", api.OTEBaseURL, api.EnvironmentOTE, false},
+		{"Given a gateway URL", "https://gw.internal/godaddy", "ote", "https://gw.internal/godaddy", api.EnvironmentOTE, false},
+		{"Given a conflicting pair", api.ProductionBaseURL, "ote", "", "", true},
+		{"Given an unknown environment", "", "staging", "", "", true},
+	}
+	for _, test := range criteria {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Setenv("GODADDY_API_URL", "")
+			t.Setenv("GODADDY_ENVIRONMENT", "")
+			cfg := godaddyProviderModel{
+				BaseURL:     types.StringNull(),
+				Environment: types.StringNull(),
+			}
+			if test.BaseURL != "" {
+				cfg.BaseURL = types.StringValue(test.BaseURL)
+			}
+			if test.Environment != "" {
+				cfg.Environment = types.StringValue(test.Environment)
+			}
+
+			baseURL, env, diags := resolveEndpoint(cfg)
+			if test.Negative {
+				assert.True(t, diags.HasError())
+				return
+			}
+			assert.False(t, diags.HasError())
+			assert.Equal(t, test.ExpectedURL, baseURL)
+			assert.Equal(t, test.ExpectedEnv, env)
+		})
+	}
+}
+
+func TestVerifyCredentials(t *testing.T) {
+	var criteria = []struct {
+		Name            string
+		Status          int
+		Body            string
+		ExpectedSummary string
+	}{
+		{"Given valid credentials", http.StatusOK, `[]`, ""},
+		{"Given a bad key", http.StatusUnauthorized, `{"code":"UNABLE_TO_AUTHENTICATE","message":"Unable to authenticate"}`, "Invalid GoDaddy API credentials"},
+		{"Given an ineligible account", http.StatusForbidden, `{"code":"ACCESS_DENIED","message":"Authenticated user is not allowed access"}`, "GoDaddy API access denied"},
+		{"Given a gateway error", http.StatusBadRequest, `<html></html>`, "Unable to verify GoDaddy credentials"},
+	}
+	for i, test := range criteria {
+		t.Run(test.Name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.Status)
+				w.Write([]byte(test.Body))
+			}))
+			defer srv.Close()
+
+			// Each case gets its own key, and so its own rate limiter.
+			client, err := api.NewClient(srv.URL, fmt.Sprintf("verify-key-%d", i), "secret")
+			assert.Nil(t, err)
+
+			diags := verifyCredentials(context.Background(), client, "provider configuration")
+			if test.ExpectedSummary == "" {
+				assert.False(t, diags.HasError())
+				return
+			}
+			assert.True(t, diags.HasError())
+			assert.Equal(t, test.ExpectedSummary, diags.Errors()[0].Summary())
+		})
+	}
+}
@@ -0,0 +1,433 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+
+	"github.com/CruGlobal/terraform-provider-godaddy/internal/api"
+)
+
+// Records carry their value in one of four attributes depending on their
+// type: MX, SRV and CAA records have an mx, srv or caa block with the fields
+// that only make sense for them, and every other type has data. Records are
+// always read back the same way, so that unrelated fields never show up in
+// a plan and GoDaddy's quoting of CAA values never shows up as drift.
+
+// recordValue is the type-specific part of a record.
+type recordValue struct {
+	Data types.String `tfsdk:"data"`
+	MX   types.Object `tfsdk:"mx"`
+	SRV  types.Object `tfsdk:"srv"`
+	CAA  types.Object `tfsdk:"caa"`
+}
+
+type mxModel struct {
+	Priority types.Int64  `tfsdk:"priority"`
+	Exchange types.String `tfsdk:"exchange"`
+}
+
+type srvModel struct {
+	Service  types.String `tfsdk:"service"`
+	Protocol types.String `tfsdk:"protocol"`
+	Priority types.Int64  `tfsdk:"priority"`
+	Weight   types.Int64  `tfsdk:"weight"`
+	Port     types.Int64  `tfsdk:"port"`
+	Target   types.String `tfsdk:"target"`
+}
+
+type caaModel struct {
+	Flags types.Int64  `tfsdk:"flags"`
+	Tag   types.String `tfsdk:"tag"`
+	Value types.String `tfsdk:"value"`
+}
+
+func mxObjectType() types.ObjectType {
+	return types.ObjectType{
+		AttrTypes: map[string]attr.Type{
+			"priority": types.Int64Type,
+			"exchange": types.StringType,
+		},
+	}
+}
+
+func srvObjectType() types.ObjectType {
+	return types.ObjectType{
+		AttrTypes: map[string]attr.Type{
+			"service":  types.StringType,
+			"protocol": types.StringType,
+			"priority": types.Int64Type,
+			"weight":   types.Int64Type,
+			"port":     types.Int64Type,
+			"target":   types.StringType,
+		},
+	}
+}
+
+func caaObjectType() types.ObjectType {
+	return types.ObjectType{
+		AttrTypes: map[string]attr.Type{
+			"flags": types.Int64Type,
+			"tag":   types.StringType,
+			"value": types.StringType,
+		},
+	}
+}
+
+// recordValueAttrTypes are the attribute types of a recordValue, for
+// building the object types of records.
+func recordValueAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"data": types.StringType,
+		"mx":   mxObjectType(),
+		"srv":  srvObjectType(),
+		"caa":  caaObjectType(),
+	}
+}
+
+// recordValueAttributes is the schema of a configured recordValue.
+func recordValueAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"data": schema.StringAttribute{
+			Description: "Record data (value), for every type but MX, SRV and CAA.",
+			Optional:    true,
+		},
+		"mx": schema.SingleNestedAttribute{
+			Description: "The fields of an MX record, in place of `data`.",
+			Optional:    true,
+			Attributes: map[string]schema.Attribute{
+				"priority": schema.Int64Attribute{
+					Description: "Priority; lower values are preferred.",
+					Required:    true,
+				},
+				"exchange": schema.StringAttribute{
+					Description: "Hostname of the mail server.",
+					Required:    true,
+				},
+			},
+		},
+		"srv": schema.SingleNestedAttribute{
+			Description: "The fields of an SRV record, in place of `data`.",
+			Optional:    true,
+			Attributes: map[string]schema.Attribute{
+				"service": schema.StringAttribute{
+					Description: "Service name, starting with an underscore, e.g. `_sip`.",
+					Required:    true,
+				},
+				"protocol": schema.StringAttribute{
+					Description: "Protocol, starting with an underscore, e.g. `_tcp`.",
+					Required:    true,
+				},
+				"priority": schema.Int64Attribute{
+					Description: "Priority; lower values are preferred. Defaults to `0`.",
+					Optional:    true,
+					Computed:    true,
+					Default:     int64default.StaticInt64(int64(api.DefaultPriority)),
+				},
+				"weight": schema.Int64Attribute{
+					Description: "Relative weight among targets of the same priority. Defaults to `0`.",
+					Optional:    true,
+					Computed:    true,
+					Default:     int64default.StaticInt64(int64(api.DefaultWeight)),
+				},
+				"port": schema.Int64Attribute{
+					Description: "Port the service listens on.",
+					Required:    true,
+				},
+				"target": schema.StringAttribute{
+					Description: "Hostname providing the service.",
+					Required:    true,
+				},
+			},
+		},
+		"caa": schema.SingleNestedAttribute{
+			Description: "The fields of a CAA record, in place of `data`.",
+			Optional:    true,
+			Attributes: map[string]schema.Attribute{
+				"flags": schema.Int64Attribute{
+					Description: "Flags; 128 marks the property as critical. Defaults to `0`.",
+					Optional:    true,
+					Computed:    true,
+					Default:     int64default.StaticInt64(0),
+				},
+				"tag": schema.StringAttribute{
+					Description: "Property tag, in lower case. One of issue, issuewild, iodef.",
+					Required:    true,
+				},
+				"value": schema.StringAttribute{
+					Description: "Property value, unquoted: a certificate authority's domain such as `letsencrypt.org` for issue and issuewild, or a `mailto:` or `https://` URL for iodef.",
+					Required:    true,
+				},
+			},
+		},
+	}
+}
+
+// computedRecordValueAttributes is recordValueAttributes for records that
+// are only ever read.
+func computedRecordValueAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"data": schema.StringAttribute{Description: "Record data (value), for every type but MX, SRV and CAA.", Computed: true},
+		"mx": schema.SingleNestedAttribute{
+			Description: "The fields of an MX record.",
+			Computed:    true,
+			Attributes: map[string]schema.Attribute{
+				"priority": schema.Int64Attribute{Description: "Priority.", Computed: true},
+				"exchange": schema.StringAttribute{Description: "Hostname of the mail server.", Computed: true},
+			},
+		},
+		"srv": schema.SingleNestedAttribute{
+			Description: "The fields of an SRV record.",
+			Computed:    true,
+			Attributes: map[string]schema.Attribute{
+				"service":  schema.StringAttribute{Description: "Service name.", Computed: true},
+				"protocol": schema.StringAttribute{Description: "Protocol.", Computed: true},
+				"priority": schema.Int64Attribute{Description: "Priority.", Computed: true},
+				"weight":   schema.Int64Attribute{Description: "Weight.", Computed: true},
+				"port":     schema.Int64Attribute{Description: "Port.", Computed: true},
+				"target":   schema.StringAttribute{Description: "Hostname providing the service.", Computed: true},
+			},
+		},
+		"caa": schema.SingleNestedAttribute{
+			Description: "The fields of a CAA record.",
+			Computed:    true,
+			Attributes: map[string]schema.Attribute{
+				"flags": schema.Int64Attribute{Description: "Flags.", Computed: true},
+				"tag":   schema.StringAttribute{Description: "Property tag.", Computed: true},
+				"value": schema.StringAttribute{Description: "Property value.", Computed: true},
+			},
+		},
+	}
+}
+
+// valueAttribute is the attribute that holds the value of records of type t.
+func valueAttribute(t string) string {
+	switch strings.ToUpper(t) {
+	case api.MXType:
+		return "mx"
+	case api.SRVType:
+		return "srv"
+	case api.CAAType:
+		return "caa"
+	}
+	return "data"
+}
+
+// build validates a configured record of type t and converts it to an API
+// record. elemPath locates the record for errors.
+func (v recordValue) build(ctx context.Context, elemPath path.Path, name, t string, ttl int) (*api.DomainRecord, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	want := valueAttribute(t)
+	set := map[string]bool{
+		"data": !v.Data.IsNull(),
+		"mx":   !v.MX.IsNull(),
+		"srv":  !v.SRV.IsNull(),
+		"caa":  !v.CAA.IsNull(),
+	}
+	for _, a := range []string{"data", "mx", "srv", "caa"} {
+		if set[a] && a != want {
+			diags.AddAttributeError(elemPath.AtName(a), "Unexpected record value",
+				fmt.Sprintf("%s records are configured with %s, not %s.", t, want, a))
+		}
+	}
+	if !set[want] {
+		diags.AddAttributeError(elemPath.AtName(want), "Missing record value", fmt.Sprintf("%s records need %s.", t, want))
+	}
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	var rec *api.DomainRecord
+	var err error
+	switch want {
+	case "mx":
+		var m mxModel
+		diags.Append(v.MX.As(ctx, &m, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		rec, err = api.NewDomainRecord(name, t, m.Exchange.ValueString(), ttl, api.Priority(int(m.Priority.ValueInt64())))
+	case "srv":
+		var s srvModel
+		diags.Append(v.SRV.As(ctx, &s, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		rec, err = api.NewDomainRecord(name, t, s.Target.ValueString(), ttl,
+			api.Priority(int(s.Priority.ValueInt64())),
+			api.Weight(int(s.Weight.ValueInt64())),
+			api.Port(int(s.Port.ValueInt64())),
+			api.Service(s.Service.ValueString()),
+			api.Protocol(s.Protocol.ValueString()),
+		)
+	case "caa":
+		var c caaModel
+		diags.Append(v.CAA.As(ctx, &c, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		// GoDaddy stores tags in lower case, so any other case would read
+		// back as a different value than was planned.
+		if tag := c.Tag.ValueString(); tag != strings.ToLower(tag) {
+			diags.AddAttributeError(elemPath.AtName("caa").AtName("tag"), "Invalid record",
+				fmt.Sprintf("CAA tags must be lower case, e.g. %q.", strings.ToLower(tag)))
+			return nil, diags
+		}
+		rec, err = api.NewCAARecord(name, int(c.Flags.ValueInt64()), c.Tag.ValueString(), c.Value.ValueString(), ttl)
+	default:
+		rec, err = api.NewDomainRecord(name, t, v.Data.ValueString(), ttl)
+	}
+	if err != nil {
+		p := elemPath
+		if want != "data" {
+			p = elemPath.AtName(want)
+		}
+		diags.AddAttributeError(p, "Invalid record", err.Error())
+		return nil, diags
+	}
+	return rec, diags
+}
+
+// record converts a record already in state back into an API record,
+// without validating it.
+func (v recordValue) record(ctx context.Context, name, t string, ttl int) (*api.DomainRecord, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	rec := &api.DomainRecord{Name: name, Type: t, Data: v.Data.ValueString(), TTL: ttl}
+	switch {
+	case !v.MX.IsNull() && !v.MX.IsUnknown():
+		var m mxModel
+		diags.Append(v.MX.As(ctx, &m, basetypes.ObjectAsOptions{})...)
+		rec.Data, rec.Priority = m.Exchange.ValueString(), int(m.Priority.ValueInt64())
+	case !v.SRV.IsNull() && !v.SRV.IsUnknown():
+		var s srvModel
+		diags.Append(v.SRV.As(ctx, &s, basetypes.ObjectAsOptions{})...)
+		rec.Data, rec.Priority, rec.Weight = s.Target.ValueString(), int(s.Priority.ValueInt64()), int(s.Weight.ValueInt64())
+		rec.Service, rec.Protocol = s.Service.ValueString(), s.Protocol.ValueString()
+		if port := int(s.Port.ValueInt64()); port != 0 {
+			rec.Port = &port
+		}
+	case !v.CAA.IsNull() && !v.CAA.IsUnknown():
+		var c caaModel
+		diags.Append(v.CAA.As(ctx, &c, basetypes.ObjectAsOptions{})...)
+		rec.Data = api.FormatCAAData(int(c.Flags.ValueInt64()), c.Tag.ValueString(), c.Value.ValueString())
+	}
+	return rec, diags
+}
+
+// newRecordValue splits a record read from GoDaddy into its recordValue.
+// CAA data that can't be parsed is kept as data.
+func newRecordValue(rec *api.DomainRecord) (recordValue, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	v := recordValue{
+		Data: types.StringValue(rec.Data),
+		MX:   types.ObjectNull(mxObjectType().AttrTypes),
+		SRV:  types.ObjectNull(srvObjectType().AttrTypes),
+		CAA:  types.ObjectNull(caaObjectType().AttrTypes),
+	}
+
+	var d diag.Diagnostics
+	switch valueAttribute(rec.Type) {
+	case "mx":
+		v.Data = types.StringNull()
+		v.MX, d = types.ObjectValue(mxObjectType().AttrTypes, map[string]attr.Value{
+			"priority": types.Int64Value(int64(rec.Priority)),
+			"exchange": types.StringValue(rec.Data),
+		})
+	case "srv":
+		port := int64(0)
+		if rec.Port != nil {
+			port = int64(*rec.Port)
+		}
+		v.Data = types.StringNull()
+		v.SRV, d = types.ObjectValue(srvObjectType().AttrTypes, map[string]attr.Value{
+			"service":  types.StringValue(rec.Service),
+			"protocol": types.StringValue(rec.Protocol),
+			"priority": types.Int64Value(int64(rec.Priority)),
+			"weight":   types.Int64Value(int64(rec.Weight)),
+			"port":     types.Int64Value(port),
+			"target":   types.StringValue(rec.Data),
+		})
+	case "caa":
+		flags, tag, value, err := api.ParseCAAData(rec.Data)
+		if err != nil {
+			break
+		}
+		v.Data = types.StringNull()
+		v.CAA, d = types.ObjectValue(caaObjectType().AttrTypes, map[string]attr.Value{
+			"flags": types.Int64Value(int64(flags)),
+			"tag":   types.StringValue(tag),
+			"value": types.StringValue(value),
+		})
+	}
+	diags.Append(d...)
+	return v, diags
+}
+
+// attributes returns the value's attributes, for building a record object.
+func (v recordValue) attributes() map[string]attr.Value {
+	return map[string]attr.Value{
+		"data": v.Data,
+		"mx":   v.MX,
+		"srv":  v.SRV,
+		"caa":  v.CAA,
+	}
+}
+
+// recordValueSet converts records read from GoDaddy into a set of objType
+// objects, each holding the record's value and the attributes that fields
+// returns for it. fields may be nil.
+func recordValueSet(recs []*api.DomainRecord, objType types.ObjectType, fields func(*api.DomainRecord) map[string]attr.Value) (types.Set, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	objs := make([]attr.Value, 0, len(recs))
+	for _, r := range recs {
+		v, d := newRecordValue(r)
+		diags.Append(d...)
+		if diags.HasError() {
+			return types.SetNull(objType), diags
+		}
+		attrs := v.attributes()
+		if fields != nil {
+			for name, value := range fields(r) {
+				attrs[name] = value
+			}
+		}
+		obj, d := types.ObjectValue(objType.AttrTypes, attrs)
+		diags.Append(d...)
+		if diags.HasError() {
+			return types.SetNull(objType), diags
+		}
+		objs = append(objs, obj)
+	}
+	set, d := types.SetValue(objType, objs)
+	diags.Append(d...)
+	return set, diags
+}
+
+// recordFieldPath resolves a GoDaddy DNSRecord field of a record of type t
+// to the attribute that configures it, relative to the record's elemPath.
+func recordFieldPath(elemPath path.Path, t, field string) (path.Path, bool) {
+	block := valueAttribute(t)
+	switch {
+	case field == "data" && block == "data":
+		return elemPath.AtName("data"), true
+	case field == "data" && block == "mx":
+		return elemPath.AtName("mx").AtName("exchange"), true
+	case field == "data" && block == "srv":
+		return elemPath.AtName("srv").AtName("target"), true
+	case field == "data" && block == "caa":
+		return elemPath.AtName("caa"), true
+	case field == "priority" && (block == "mx" || block == "srv"):
+		return elemPath.AtName(block).AtName("priority"), true
+	case block == "srv" && (field == "weight" || field == "port" || field == "service" || field == "protocol"):
+		return elemPath.AtName("srv").AtName(field), true
+	}
+	return path.Empty(), false
+}
@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CruGlobal/terraform-provider-godaddy/internal/api"
+)
+
+func TestRecordValueBuild(t *testing.T) {
+	mx := types.ObjectValueMust(mxObjectType().AttrTypes, map[string]attr.Value{
+		"priority": types.Int64Value(10),
+		"exchange": types.StringValue("mail.example.com"),
+	})
+	srv := types.ObjectValueMust(srvObjectType().AttrTypes, map[string]attr.Value{
+		"service":  types.StringValue("_sip"),
+		"protocol": types.StringValue("_tcp"),
+		"priority": types.Int64Value(0),
+		"weight":   types.Int64Value(5),
+		"port":     types.Int64Value(5060),
+		"target":   types.StringValue("sip.example.com"),
+	})
+	caa := func(tag, value string) types.Object {
+		return types.ObjectValueMust(caaObjectType().AttrTypes, map[string]attr.Value{
+			"flags": types.Int64Value(0),
+			"tag":   types.StringValue(tag),
+			"value": types.StringValue(value),
+		})
+	}
+	value := func(data types.String, mx, srv, caa types.Object) recordValue {
+		v := recordValue{
+			Data: data,
+			MX:   types.ObjectNull(mxObjectType().AttrTypes),
+			SRV:  types.ObjectNull(srvObjectType().AttrTypes),
+			CAA:  types.ObjectNull(caaObjectType().AttrTypes),
+		}
+		if !mx.IsNull() {
+			v.MX = mx
+		}
+		if !srv.IsNull() {
+			v.SRV = srv
+		}
+		if !caa.IsNull() {
+			v.CAA = caa
+		}
+		return v
+	}
+	none := types.ObjectNull(map[string]attr.Type{})
+	port := 5060
+
+	var criteria = []struct {
+		Name     string
+		Type     string
+		Value    recordValue
+		Expected *api.DomainRecord
+		Negative bool
+	}{
+		{"Given data", api.TXTType, value(types.StringValue("v=spf1 -all"), none, none, none),
+			&api.DomainRecord{Type: api.TXTType, Name: "@", Data: "v=spf1 -all", TTL: api.DefaultTTL}, false},
+		{"Given MX fields", api.MXType, value(types.StringNull(), mx, none, none),
+			&api.DomainRecord{Type: api.MXType, Name: "@", Data: "mail.example.com", Priority: 10, TTL: api.DefaultTTL}, false},
+		{"Given SRV fields", api.SRVType, value(types.StringNull(), none, srv, none),
+			&api.DomainRecord{Type: api.SRVType, Name: "@", Data: "sip.example.com", Service: "_sip", Protocol: "_tcp", Weight: 5, Port: &port, TTL: api.DefaultTTL}, false},
+		{"Given CAA fields", api.CAAType, value(types.StringNull(), none, none, caa("issue", "letsencrypt.org")),
+			&api.DomainRecord{Type: api.CAAType, Name: "@", Data: `0 issue "letsencrypt.org"`, TTL: api.DefaultTTL}, false},
+		{"Given MX data", api.MXType, value(types.StringValue("mail.example.com"), none, none, none), nil, true},
+		{"Given CAA data", api.CAAType, value(types.StringValue(`0 issue "letsencrypt.org"`), none, none, none), nil, true},
+		{"Given MX fields on an A record", api.AType, value(types.StringValue("192.0.2.1"), mx, none, none), nil, true},
+		{"Given SRV fields on an MX record", api.MXType, value(types.StringNull(), mx, srv, none), nil, true},
+		{"Given an upper-case CAA tag", api.CAAType, value(types.StringNull(), none, none, caa("Issue", "letsencrypt.org")), nil, true},
+		{"Given an invalid CAA tag", api.CAAType, value(types.StringNull(), none, none, caa("issuer", "letsencrypt.org")), nil, true},
+		{"Given nothing", api.TXTType, value(types.StringNull(), none, none, none), nil, true},
+	}
+	for _, test := range criteria {
+		t.Run(test.Name, func(t *testing.T) {
+			rec, d := test.Value.build(context.Background(), path.Root("record"), "@", test.Type, api.DefaultTTL)
+			assert.Equal(t, test.Negative, d.HasError())
+			if !test.Negative {
+				assert.True(t, api.SameRecord(test.Expected, rec), "got %+v", rec)
+			}
+		})
+	}
+}
+
+func TestRecordValueRoundTrip(t *testing.T) {
+	port := 443
+	var criteria = []struct {
+		Name   string
+		Record *api.DomainRecord
+		Block  string
+	}{
+		{"Given an A record", &api.DomainRecord{Type: api.AType, Name: "@", Data: "192.0.2.1", TTL: api.DefaultTTL}, "data"},
+		{"Given an MX record", &api.DomainRecord{Type: api.MXType, Name: "@", Data: "mail.example.com", Priority: 10, TTL: api.DefaultTTL}, "mx"},
+		{"Given an SRV record", &api.DomainRecord{Type: api.SRVType, Name: "@", Data: "host.example.com", Service: "_https", Protocol: "_tcp", Priority: 1, Weight: 2, Port: &port, TTL: api.DefaultTTL}, "srv"},
+		// GoDaddy may return CAA values unquoted; they read back as the same fields.
+		{"Given an unquoted CAA record", &api.DomainRecord{Type: api.CAAType, Name: "@", Data: "0 issue letsencrypt.org", TTL: api.DefaultTTL}, "caa"},
+		{"Given a CAA record with extra whitespace", &api.DomainRecord{Type: api.CAAType, Name: "@", Data: `0  issue  "letsencrypt.org"`, TTL: api.DefaultTTL}, "caa"},
+	}
+	for _, test := range criteria {
+		t.Run(test.Name, func(t *testing.T) {
+			v, d := newRecordValue(test.Record)
+			assert.False(t, d.HasError())
+			for name, value := range v.attributes() {
+				assert.Equal(t, name != test.Block, value.IsNull(), name)
+			}
+
+			rec, d := v.build(context.Background(), path.Root("record"), test.Record.Name, test.Record.Type, test.Record.TTL)
+			assert.False(t, d.HasError())
+			assert.True(t, api.SameRecord(test.Record, rec), "got %+v", rec)
+
+			rec, d = v.record(context.Background(), test.Record.Name, test.Record.Type, test.Record.TTL)
+			assert.False(t, d.HasError())
+			assert.True(t, api.SameRecord(test.Record, rec), "got %+v", rec)
+		})
+	}
+}
+
+func TestRecordFieldPath(t *testing.T) {
+	elem := path.Root("record").AtListIndex(0)
+	var criteria = []struct {
+		Type     string
+		Field    string
+		Expected path.Path
+		Negative bool
+	}{
+		{api.AType, "data", elem.AtName("data"), false},
+		{api.MXType, "data", elem.AtName("mx").AtName("exchange"), false},
+		{api.MXType, "priority", elem.AtName("mx").AtName("priority"), false},
+		{api.SRVType, "data", elem.AtName("srv").AtName("target"), false},
+		{api.SRVType, "port", elem.AtName("srv").AtName("port"), false},
+		{api.CAAType, "data", elem.AtName("caa"), false},
+		{api.AType, "priority", path.Empty(), true},
+		{api.MXType, "weight", path.Empty(), true},
+	}
+	for _, test := range criteria {
+		t.Run(test.Type+"."+test.Field, func(t *testing.T) {
+			p, ok := recordFieldPath(elem, test.Type, test.Field)
+			assert.Equal(t, !test.Negative, ok)
+			if !test.Negative {
+				assert.True(t, test.Expected.Equal(p), "got %s", p)
+			}
+		})
+	}
+}
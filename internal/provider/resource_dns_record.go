@@ -0,0 +1,371 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/CruGlobal/terraform-provider-godaddy/internal/api"
+)
+
+var (
+	_ resource.Resource                = &dnsRecordResource{}
+	_ resource.ResourceWithConfigure   = &dnsRecordResource{}
+	_ resource.ResourceWithImportState = &dnsRecordResource{}
+	_ resource.ResourceWithModifyPlan  = &dnsRecordResource{}
+)
+
+func NewDNSRecordResource() resource.Resource {
+	return &dnsRecordResource{}
+}
+
+// dnsRecordResource manages the records of a single type and name on a
+// domain, leaving every other record alone.
+type dnsRecordResource struct {
+	client *api.Client
+	data   *providerData
+}
+
+type dnsRecordResourceModel struct {
+	ID       types.String `tfsdk:"id"`
+	Domain   types.String `tfsdk:"domain"`
+	Customer types.String `tfsdk:"customer"`
+	Type     types.String `tfsdk:"type"`
+	Name     types.String `tfsdk:"name"`
+	TTL      types.Int64  `tfsdk:"ttl"`
+	Values   types.Set    `tfsdk:"values"`
+}
+
+func dnsRecordValueObjectType() types.ObjectType {
+	return types.ObjectType{AttrTypes: recordValueAttrTypes()}
+}
+
+func (r *dnsRecordResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_dns_record"
+}
+
+func (r *dnsRecordResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "`godaddy_dns_record` manages the DNS records of one type and name on a domain registered with GoDaddy. Other records on the domain are left alone.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The domain, type and name of the record set, as `domain/TYPE/name`.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"domain": schema.StringAttribute{
+				Description: "The domain name the records belong to.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"customer": schema.StringAttribute{
+				Description: "GoDaddy customer (shopper) ID. Required when the API key does not belong to the customer owning the domain. Defaults to the provider's `customer`.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"type": schema.StringAttribute{
+				Description: "Record type. One of A, AAAA, CAA, CNAME, MX, NS, SRV, TXT.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "Record name (subdomain). Use `@` for the root.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"ttl": schema.Int64Attribute{
+				Description: "TTL in seconds, shared by every record in the set.",
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(int64(api.DefaultTTL)),
+			},
+			"values": schema.SetNestedAttribute{
+				Description: "The records in the set. At least one is required; destroy the resource to remove them all.",
+				Required:    true,
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: recordValueAttributes(),
+				},
+			},
+		},
+	}
+}
+
+func (r *dnsRecordResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected provider data",
+			fmt.Sprintf("Expected *providerData, got %T. Please report this to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	r.client = data.client
+	r.data = data
+}
+
+func (r *dnsRecordResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	r.data.planCustomer(ctx, req, resp)
+}
+
+func (r *dnsRecordResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan dnsRecordResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Taking over records that something else created would leave two owners
+	// fighting over them, so existing records have to be imported instead. The
+	// check runs under the domain's lock, so that two resources creating the
+	// same records can't both pass it.
+	managed := recordsAt(plan.Type.ValueString(), plan.Name.ValueString())
+	check := func(records []*api.DomainRecord) error {
+		count := 0
+		for _, rec := range records {
+			if managed(rec) {
+				count++
+			}
+		}
+		if count > 0 {
+			return &recordsExistError{model: &plan, count: count}
+		}
+		return nil
+	}
+
+	resp.Diagnostics.Append(r.apply(ctx, &plan, check)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *dnsRecordResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state dnsRecordResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	records, err := r.client.GetDomainRecordsByTypeAndName(ctx, state.Customer.ValueString(), state.Domain.ValueString(), state.Type.ValueString(), state.Name.ValueString())
+	if api.IsNotFound(err) || (err == nil && len(records) == 0) {
+		tflog.Warn(ctx, "records no longer exist, removing from state", map[string]any{"id": dnsRecordID(&state)})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Couldn't read domain records", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(setDNSRecordValues(&state, records)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *dnsRecordResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan dnsRecordResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(r.apply(ctx, &plan, nil)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *dnsRecordResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state dnsRecordResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	domain := state.Domain.ValueString()
+	unlock, err := lockDomain(ctx, domain)
+	if err != nil {
+		resp.Diagnostics.AddError("Cancelled while waiting for other changes to the domain", err.Error())
+		return
+	}
+	defer unlock()
+
+	// The records are deleted directly rather than synced to an empty set,
+	// which would leave NS records in place.
+	tflog.Info(ctx, "deleting DNS records", map[string]any{"id": dnsRecordID(&state)})
+	err = r.client.DeleteDomainRecordsByTypeAndName(ctx, state.Customer.ValueString(), domain, state.Type.ValueString(), state.Name.ValueString())
+	if err != nil && !api.IsNotFound(err) {
+		resp.Diagnostics.AddError("Failed to delete records", err.Error())
+	}
+}
+
+func (r *dnsRecordResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	domain, recordType, name, err := parseDNSRecordID(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("domain"), domain)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("type"), recordType)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), name)...)
+	r.data.importCustomer(ctx, resp)
+}
+
+// apply writes the planned record set, leaving the domain's other records
+// untouched, and reads it back into plan. check, if set, vets the domain's
+// records before anything is written.
+func (r *dnsRecordResource) apply(ctx context.Context, plan *dnsRecordResourceModel, check func([]*api.DomainRecord) error) diag.Diagnostics {
+	var diags diag.Diagnostics
+	customer := plan.Customer.ValueString()
+	domain := plan.Domain.ValueString()
+	plan.ID = types.StringValue(dnsRecordID(plan))
+
+	records, sources, d := buildDNSRecords(ctx, plan)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	tflog.Info(ctx, "updating DNS records", map[string]any{"id": plan.ID.ValueString(), "records": len(records)})
+	update := recordUpdate{desired: records, managed: recordsAt(plan.Type.ValueString(), plan.Name.ValueString()), check: check}
+	changes, err := r.data.syncRecords(ctx, customer, domain, update)
+	if changes != nil {
+		tflog.Info(ctx, "domain record changes", map[string]any{"domain": domain, "changes": changes.String()})
+	}
+	var existsErr *recordsExistError
+	if errors.As(err, &existsErr) {
+		diags.AddError("Records already exist", err.Error())
+		return diags
+	}
+	if err != nil {
+		addAPIError(&diags, "Failed to update records", err, sources.fieldPath(err))
+		var rollbackErr *api.RecordRollbackError
+		if errors.As(err, &rollbackErr) {
+			addRollbackResult(&diags, rollbackErr)
+		}
+		return diags
+	}
+
+	current, err := r.client.GetDomainRecordsByTypeAndName(ctx, customer, domain, plan.Type.ValueString(), plan.Name.ValueString())
+	if err != nil {
+		diags.AddError("Couldn't read domain records", err.Error())
+		return diags
+	}
+	diags.Append(setDNSRecordValues(plan, current)...)
+	return diags
+}
+
+// buildDNSRecords converts the planned values into API records, validating
+// each one.
+func buildDNSRecords(ctx context.Context, plan *dnsRecordResourceModel) ([]*api.DomainRecord, recordSources, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	recordType := plan.Type.ValueString()
+	name := plan.Name.ValueString()
+	ttl := int(plan.TTL.ValueInt64())
+
+	out := []*api.DomainRecord{}
+	sources := recordSources{}
+	for _, elem := range plan.Values.Elements() {
+		elemPath := path.Root("values").AtSetValue(elem)
+		obj, ok := elem.(types.Object)
+		if !ok {
+			diags.AddAttributeError(elemPath, "Invalid record", fmt.Sprintf("unexpected record value %T", elem))
+			return nil, nil, diags
+		}
+		var v recordValue
+		diags.Append(obj.As(ctx, &v, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return nil, nil, diags
+		}
+		rec, d := v.build(ctx, elemPath, name, recordType, ttl)
+		diags.Append(d...)
+		if diags.HasError() {
+			return nil, nil, diags
+		}
+		out = append(out, rec)
+		sources[rec] = recordSource{path: elemPath, nested: true, root: true}
+	}
+
+	if api.IsDisallowed(recordType, out) {
+		diags.AddAttributeError(path.Root("type"), "Unsupported record type", fmt.Sprintf("%s records can't be managed by this provider.", recordType))
+		return nil, nil, diags
+	}
+	return out, sources, diags
+}
+
+// setDNSRecordValues stores the records read from GoDaddy on the model. The
+// set's TTL is taken from its first record.
+func setDNSRecordValues(m *dnsRecordResourceModel, records []*api.DomainRecord) diag.Diagnostics {
+	var diags diag.Diagnostics
+	m.ID = types.StringValue(dnsRecordID(m))
+	if len(records) > 0 {
+		m.TTL = types.Int64Value(int64(records[0].TTL))
+	}
+
+	values, d := recordValueSet(records, dnsRecordValueObjectType(), nil)
+	diags.Append(d...)
+	m.Values = values
+	return diags
+}
+
+// recordsExistError is returned when the records a godaddy_dns_record is
+// being created for are already on the domain.
+type recordsExistError struct {
+	model *dnsRecordResourceModel
+	count int
+}
+
+func (e *recordsExistError) Error() string {
+	return fmt.Sprintf("%s already has %d %s records named %q. Import them to manage them with this resource: terraform import <address> %s",
+		e.model.Domain.ValueString(), e.count, e.model.Type.ValueString(), e.model.Name.ValueString(), dnsRecordID(e.model))
+}
+
+// recordsAt returns a filter that selects the records of type t named name.
+func recordsAt(t, name string) api.RecordFilter {
+	return func(rec *api.DomainRecord) bool {
+		return strings.EqualFold(rec.Type, t) && strings.EqualFold(rec.Name, name)
+	}
+}
+
+func dnsRecordID(m *dnsRecordResourceModel) string {
+	return m.Domain.ValueString() + "/" + m.Type.ValueString() + "/" + m.Name.ValueString()
+}
+
+// parseDNSRecordID splits an import ID of the form domain/TYPE/name.
+func parseDNSRecordID(id string) (domain, recordType, name string, err error) {
+	parts := strings.SplitN(id, "/", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", fmt.Errorf("expected an ID of the form domain/TYPE/name, e.g. example.com/TXT/_dmarc, got %q", id)
+	}
+	return parts[0], strings.ToUpper(parts[1]), parts[2], nil
+}
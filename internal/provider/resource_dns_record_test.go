@@ -0,0 +1,219 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CruGlobal/terraform-provider-godaddy/internal/api"
+)
+
+func TestParseDNSRecordID(t *testing.T) {
+	var criteria = []struct {
+		Name     string
+		ID       string
+		Expected []string
+		Negative bool
+	}{
+		{"Given a record set ID", "example.com/TXT/_dmarc", []string{"example.com", "TXT", "_dmarc"}, false},
+		{"Given the root name", "example.com/a/@", []string{"example.com", "A", "@"}, false},
+		{"Given only a domain", "example.com", nil, true},
+		{"Given an empty name", "example.com/TXT/", nil, true},
+	}
+	for _, test := range criteria {
+		t.Run(test.Name, func(t *testing.T) {
+			domain, recordType, name, err := parseDNSRecordID(test.ID)
+			if test.Negative {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.Expected, []string{domain, recordType, name})
+		})
+	}
+}
+
+func TestRecordsAt(t *testing.T) {
+	managed := recordsAt(api.TXTType, "_dmarc")
+
+	assert.True(t, managed(&api.DomainRecord{Type: "txt", Name: "_DMARC", Data: "v=DMARC1; p=reject"}))
+	assert.False(t, managed(&api.DomainRecord{Type: api.TXTType, Name: "@", Data: "v=spf1 -all"}))
+	assert.False(t, managed(&api.DomainRecord{Type: api.CNameType, Name: "_dmarc", Data: "dmarc.example.net"}))
+}
+
+// dnsRecordModel is a godaddy_dns_record for TXT records named name on
+// example.com, holding data.
+func dnsRecordModel(name string, data ...string) *dnsRecordResourceModel {
+	values := make([]attr.Value, 0, len(data))
+	for _, d := range data {
+		v := recordValue{
+			Data: types.StringValue(d),
+			MX:   types.ObjectNull(mxObjectType().AttrTypes),
+			SRV:  types.ObjectNull(srvObjectType().AttrTypes),
+			CAA:  types.ObjectNull(caaObjectType().AttrTypes),
+		}
+		values = append(values, types.ObjectValueMust(recordValueAttrTypes(), v.attributes()))
+	}
+	m := &dnsRecordResourceModel{
+		Domain:   types.StringValue("example.com"),
+		Customer: types.StringNull(),
+		Type:     types.StringValue(api.TXTType),
+		Name:     types.StringValue(name),
+		TTL:      types.Int64Value(api.DefaultTTL),
+		Values:   types.SetValueMust(dnsRecordValueObjectType(), values),
+	}
+	m.ID = types.StringValue(dnsRecordID(m))
+	return m
+}
+
+// dnsRecordState returns state holding m, or no resource when m is nil.
+func dnsRecordState(t *testing.T, r *dnsRecordResource, m *dnsRecordResourceModel) tfsdk.State {
+	var resp resource.SchemaResponse
+	r.Schema(context.Background(), resource.SchemaRequest{}, &resp)
+	state := tfsdk.State{Schema: resp.Schema}
+	if m != nil {
+		d := state.Set(context.Background(), m)
+		assert.False(t, d.HasError(), "%v", d)
+	}
+	return state
+}
+
+func TestDNSRecordCreate(t *testing.T) {
+	var criteria = []struct {
+		Name     string
+		Existing []*api.DomainRecord
+		Expected []string
+		Negative bool
+	}{
+		{"Given no records at the name", []*api.DomainRecord{
+			{Type: api.TXTType, Name: "@", Data: "v=spf1 -all", TTL: api.DefaultTTL},
+		}, []string{"TXT @ v=spf1 -all", "TXT _dmarc v=DMARC1; p=reject"}, false},
+		{"Given records already at the name", []*api.DomainRecord{
+			{Type: api.TXTType, Name: "_dmarc", Data: "v=DMARC1; p=none", TTL: api.DefaultTTL},
+		}, []string{"TXT _dmarc v=DMARC1; p=none"}, true},
+	}
+	for _, test := range criteria {
+		t.Run(test.Name, func(t *testing.T) {
+			zone, client := newFakeZone(t, "example.com", test.Existing...)
+			r := &dnsRecordResource{client: client, data: &providerData{client: client}}
+
+			plan := dnsRecordState(t, r, dnsRecordModel("_dmarc", "v=DMARC1; p=reject"))
+			req := resource.CreateRequest{Plan: tfsdk.Plan{Schema: plan.Schema, Raw: plan.Raw}}
+			resp := resource.CreateResponse{State: dnsRecordState(t, r, nil)}
+			r.Create(context.Background(), req, &resp)
+
+			assert.Equal(t, test.Negative, resp.Diagnostics.HasError())
+			if test.Negative {
+				assert.Equal(t, "Records already exist", resp.Diagnostics.Errors()[0].Summary())
+			}
+			assert.ElementsMatch(t, test.Expected, zone.ofType(api.TXTType))
+		})
+	}
+}
+
+func TestDNSRecordCreateConcurrently(t *testing.T) {
+	for _, coalesce := range []bool{false, true} {
+		t.Run(fmt.Sprintf("coalesce=%t", coalesce), func(t *testing.T) {
+			zone, client := newFakeZone(t, "example.com")
+			data := &providerData{client: client}
+			if coalesce {
+				data.coalescer = newRecordCoalescer()
+			}
+			r := &dnsRecordResource{client: client, data: data}
+
+			// Two resources creating the same records: only one may win.
+			values := []string{"v=DMARC1; p=reject", "v=DMARC1; p=none"}
+			errs := make([]bool, len(values))
+			var wg sync.WaitGroup
+			for i, v := range values {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					plan := dnsRecordState(t, r, dnsRecordModel("_dmarc", v))
+					req := resource.CreateRequest{Plan: tfsdk.Plan{Schema: plan.Schema, Raw: plan.Raw}}
+					resp := resource.CreateResponse{State: dnsRecordState(t, r, nil)}
+					r.Create(context.Background(), req, &resp)
+					errs[i] = resp.Diagnostics.HasError()
+					if errs[i] {
+						assert.Equal(t, "Records already exist", resp.Diagnostics.Errors()[0].Summary())
+					}
+				}()
+			}
+			wg.Wait()
+
+			assert.ElementsMatch(t, []bool{false, true}, errs)
+			assert.Len(t, zone.ofType(api.TXTType), 1)
+		})
+	}
+}
+
+func TestDNSRecordRead(t *testing.T) {
+	zone, client := newFakeZone(t, "example.com",
+		&api.DomainRecord{Type: api.TXTType, Name: "_dmarc", Data: "v=DMARC1; p=none", TTL: 3600},
+	)
+	r := &dnsRecordResource{client: client, data: &providerData{client: client}}
+	ctx := context.Background()
+
+	// Changes made outside Terraform are read back.
+	state := dnsRecordState(t, r, dnsRecordModel("_dmarc", "v=DMARC1; p=reject"))
+	resp := resource.ReadResponse{State: state}
+	r.Read(ctx, resource.ReadRequest{State: state}, &resp)
+	assert.False(t, resp.Diagnostics.HasError())
+	var read dnsRecordResourceModel
+	assert.False(t, resp.State.Get(ctx, &read).HasError())
+	assert.Equal(t, int64(3600), read.TTL.ValueInt64())
+	assert.True(t, dnsRecordModel("_dmarc", "v=DMARC1; p=none").Values.Equal(read.Values), "got %s", read.Values)
+
+	// The resource is removed from state once the set is gone.
+	zone.set(&api.DomainRecord{Type: api.TXTType, Name: "@", Data: "v=spf1 -all", TTL: api.DefaultTTL})
+	resp = resource.ReadResponse{State: state}
+	r.Read(ctx, resource.ReadRequest{State: state}, &resp)
+	assert.False(t, resp.Diagnostics.HasError())
+	assert.True(t, resp.State.Raw.IsNull())
+}
+
+func TestDNSRecordDelete(t *testing.T) {
+	zone, client := newFakeZone(t, "example.com",
+		&api.DomainRecord{Type: api.TXTType, Name: "_dmarc", Data: "v=DMARC1; p=reject", TTL: api.DefaultTTL},
+		&api.DomainRecord{Type: api.TXTType, Name: "@", Data: "v=spf1 -all", TTL: api.DefaultTTL},
+		&api.DomainRecord{Type: api.CNameType, Name: "_dmarc", Data: "dmarc.example.net", TTL: api.DefaultTTL},
+	)
+	r := &dnsRecordResource{client: client, data: &providerData{client: client}}
+	ctx := context.Background()
+
+	state := dnsRecordState(t, r, dnsRecordModel("_dmarc", "v=DMARC1; p=reject"))
+	resp := resource.DeleteResponse{State: state}
+	r.Delete(ctx, resource.DeleteRequest{State: state}, &resp)
+	assert.False(t, resp.Diagnostics.HasError())
+	assert.Equal(t, []string{"TXT @ v=spf1 -all"}, zone.ofType(api.TXTType))
+	assert.Equal(t, []string{"CNAME _dmarc dmarc.example.net"}, zone.ofType(api.CNameType))
+
+	// Records that are already gone aren't an error.
+	resp = resource.DeleteResponse{State: state}
+	r.Delete(ctx, resource.DeleteRequest{State: state}, &resp)
+	assert.False(t, resp.Diagnostics.HasError())
+}
+
+func TestDNSRecordValuesNotEmpty(t *testing.T) {
+	r := &dnsRecordResource{}
+	state := dnsRecordState(t, r, nil)
+	values := state.Schema.GetAttributes()["values"].(schema.SetNestedAttribute)
+
+	for _, set := range []types.Set{dnsRecordModel("_dmarc").Values, dnsRecordModel("_dmarc", "v=DMARC1; p=reject").Values} {
+		var resp validator.SetResponse
+		for _, v := range values.SetValidators() {
+			v.ValidateSet(context.Background(), validator.SetRequest{Path: path.Root("values"), ConfigValue: set}, &resp)
+		}
+		assert.Equal(t, len(set.Elements()) == 0, resp.Diagnostics.HasError(), "%s", set)
+	}
+}
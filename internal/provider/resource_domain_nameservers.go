@@ -28,6 +28,7 @@ var (
 	_ resource.Resource                = &domainNameserversResource{}
 	_ resource.ResourceWithConfigure   = &domainNameserversResource{}
 	_ resource.ResourceWithImportState = &domainNameserversResource{}
+	_ resource.ResourceWithModifyPlan  = &domainNameserversResource{}
 )
 
 func NewDomainNameserversResource() resource.Resource {
@@ -36,6 +37,7 @@ func NewDomainNameserversResource() resource.Resource {
 
 type domainNameserversResource struct {
 	client *api.Client
+	data   *providerData
 }
 
 type domainNameserversResourceModel struct {
@@ -68,8 +70,9 @@ func (r *domainNameserversResource) Schema(_ context.Context, _ resource.SchemaR
 				},
 			},
 			"customer": schema.StringAttribute{
-				Description: "Optional GoDaddy customer (shopper) ID.",
+				Description: "GoDaddy customer (shopper) ID. Defaults to the provider's `customer`.",
 				Optional:    true,
+				Computed:    true,
 			},
 			"nameservers": schema.ListAttribute{
 				Description: "List of nameserver hostnames.",
@@ -84,15 +87,20 @@ func (r *domainNameserversResource) Configure(_ context.Context, req resource.Co
 	if req.ProviderData == nil {
 		return
 	}
-	client, ok := req.ProviderData.(*api.Client)
+	data, ok := req.ProviderData.(*providerData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected provider data",
-			fmt.Sprintf("Expected *api.Client, got %T. Please report this to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *providerData, got %T. Please report this to the provider developers.", req.ProviderData),
 		)
 		return
 	}
-	r.client = client
+	r.client = data.client
+	r.data = data
+}
+
+func (r *domainNameserversResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	r.data.planCustomer(ctx, req, resp)
 }
 
 func (r *domainNameserversResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -115,7 +123,12 @@ func (r *domainNameserversResource) Read(ctx context.Context, req resource.ReadR
 		return
 	}
 
-	d, err := r.client.GetDomain(state.Customer.ValueString(), state.Domain.ValueString())
+	d, err := r.client.GetDomain(ctx, state.Customer.ValueString(), state.Domain.ValueString())
+	if api.IsNotFound(err) {
+		tflog.Warn(ctx, "domain no longer exists, removing from state", map[string]any{"domain": state.Domain.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError("Couldn't read domain", err.Error())
 		return
@@ -150,18 +163,27 @@ func (r *domainNameserversResource) Delete(ctx context.Context, req resource.Del
 		return
 	}
 
+	unlock, err := lockDomain(ctx, state.Domain.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Cancelled while waiting for other changes to the domain", err.Error())
+		return
+	}
+	defer unlock()
+
 	tflog.Info(ctx, "resetting nameservers", map[string]any{"domain": state.Domain.ValueString()})
 	if err := r.client.UpdateDomain(
+		ctx,
 		state.Customer.ValueString(),
 		state.Domain.ValueString(),
 		&api.DomainPurchase{NameServers: defaultNameservers},
 	); err != nil {
-		resp.Diagnostics.AddError("Failed to reset nameservers", err.Error())
+		addAPIError(&resp.Diagnostics, "Failed to reset nameservers", err, nil)
 	}
 }
 
 func (r *domainNameserversResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("domain"), req.ID)...)
+	r.data.importCustomer(ctx, resp)
 }
 
 func (r *domainNameserversResource) apply(ctx context.Context, plan *domainNameserversResourceModel) diag.Diagnostics {
@@ -169,7 +191,7 @@ func (r *domainNameserversResource) apply(ctx context.Context, plan *domainNames
 	customer := plan.Customer.ValueString()
 	domain := plan.Domain.ValueString()
 
-	d, err := r.client.GetDomain(customer, domain)
+	d, err := r.client.GetDomain(ctx, customer, domain)
 	if err != nil {
 		diags.AddError("Couldn't find domain", err.Error())
 		return diags
@@ -181,16 +203,36 @@ func (r *domainNameserversResource) apply(ctx context.Context, plan *domainNames
 	if diags.HasError() {
 		return diags
 	}
-	for _, n := range ns {
+	for i, n := range ns {
 		if err := api.ValidateData(api.NSType, n); err != nil {
-			diags.AddError("Invalid nameserver", err.Error())
+			diags.AddAttributeError(path.Root("nameservers").AtListIndex(i), "Invalid nameserver", err.Error())
 			return diags
 		}
 	}
 
+	unlock, err := lockDomain(ctx, domain)
+	if err != nil {
+		diags.AddError("Cancelled while waiting for other changes to the domain", err.Error())
+		return diags
+	}
+	defer unlock()
+
 	tflog.Info(ctx, "setting nameservers", map[string]any{"domain": domain})
-	if err := r.client.UpdateDomain(customer, domain, &api.DomainPurchase{NameServers: ns}); err != nil {
-		diags.AddError("Failed to set nameservers", err.Error())
+	if err := r.client.UpdateDomain(ctx, customer, domain, &api.DomainPurchase{NameServers: ns}); err != nil {
+		addAPIError(&diags, "Failed to set nameservers", err, nameserversFieldPath)
 	}
 	return diags
 }
+
+// nameserversFieldPath maps GoDaddy's nameServers[i] field onto the
+// nameservers list attribute.
+func nameserversFieldPath(steps []fieldStep) (path.Path, bool) {
+	if len(steps) == 0 || steps[0].name != "nameServers" {
+		return path.Empty(), false
+	}
+	p := path.Root("nameservers")
+	if steps[0].index >= 0 {
+		p = p.AtListIndex(steps[0].index)
+	}
+	return p, true
+}
@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CruGlobal/terraform-provider-godaddy/internal/api"
+)
+
+// domainServer is a GoDaddy API that answers GET /v1/domains/example.com
+// with status, and with a domain in domainStatus when status is 200.
+func domainServer(t *testing.T, status int, domainStatus string) *api.Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/domains/example.com" {
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			json.NewEncoder(w).Encode(map[string]string{"code": http.StatusText(status), "message": "domain lookup failed"})
+			return
+		}
+		json.NewEncoder(w).Encode(api.Domain{ID: 1234, Name: "example.com", Status: domainStatus, NameServers: []string{"ns1.example.net"}})
+	}))
+	t.Cleanup(srv.Close)
+	client, err := api.NewClient(srv.URL, "domain-key-"+t.Name(), "secret", api.WithRateLimit(6000, false), api.WithRetryPolicy(api.RetryPolicy{}))
+	assert.Nil(t, err)
+	return client
+}
+
+// domainState returns state for r with only domain set to example.com.
+func domainState(t *testing.T, r resource.Resource) tfsdk.State {
+	ctx := context.Background()
+	var resp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &resp)
+	state := tfsdk.State{Schema: resp.Schema, Raw: tftypes.NewValue(resp.Schema.Type().TerraformType(ctx), nil)}
+	d := state.SetAttribute(ctx, path.Root("domain"), "example.com")
+	assert.False(t, d.HasError(), "%v", d)
+	return state
+}
+
+func TestDomainNameserversRead(t *testing.T) {
+	var criteria = []struct {
+		Name     string
+		Status   int
+		Removed  bool
+		Negative bool
+	}{
+		{"Given the domain", http.StatusOK, false, false},
+		{"Given a domain that no longer exists", http.StatusNotFound, true, false},
+		{"Given a server error", http.StatusInternalServerError, false, true},
+		{"Given a forbidden domain", http.StatusForbidden, false, true},
+	}
+	for _, test := range criteria {
+		t.Run(test.Name, func(t *testing.T) {
+			client := domainServer(t, test.Status, api.StatusActive)
+			r := &domainNameserversResource{client: client, data: &providerData{client: client}}
+			state := domainState(t, r)
+
+			resp := resource.ReadResponse{State: state}
+			r.Read(context.Background(), resource.ReadRequest{State: state}, &resp)
+			assert.Equal(t, test.Negative, resp.Diagnostics.HasError())
+			assert.Equal(t, test.Removed, resp.State.Raw.IsNull())
+			if test.Negative {
+				assert.True(t, state.Raw.Equal(resp.State.Raw), "state changed to %s", resp.State.Raw)
+			}
+		})
+	}
+}
@@ -22,6 +22,7 @@ var (
 	_ resource.Resource                = &domainPurchaseResource{}
 	_ resource.ResourceWithConfigure   = &domainPurchaseResource{}
 	_ resource.ResourceWithImportState = &domainPurchaseResource{}
+	_ resource.ResourceWithModifyPlan  = &domainPurchaseResource{}
 )
 
 func NewDomainPurchaseResource() resource.Resource {
@@ -30,6 +31,7 @@ func NewDomainPurchaseResource() resource.Resource {
 
 type domainPurchaseResource struct {
 	client *api.Client
+	data   *providerData
 }
 
 type domainPurchaseResourceModel struct {
@@ -115,8 +117,9 @@ func (r *domainPurchaseResource) Schema(_ context.Context, _ resource.SchemaRequ
 				},
 			},
 			"customer": schema.StringAttribute{
-				Description: "Optional GoDaddy customer (shopper) ID.",
+				Description: "GoDaddy customer (shopper) ID. Defaults to the provider's `customer`.",
 				Optional:    true,
+				Computed:    true,
 			},
 			"years_leased": schema.Int64Attribute{
 				Description: "Lease length in years.",
@@ -150,15 +153,20 @@ func (r *domainPurchaseResource) Configure(_ context.Context, req resource.Confi
 	if req.ProviderData == nil {
 		return
 	}
-	client, ok := req.ProviderData.(*api.Client)
+	data, ok := req.ProviderData.(*providerData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected provider data",
-			fmt.Sprintf("Expected *api.Client, got %T. Please report this to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *providerData, got %T. Please report this to the provider developers.", req.ProviderData),
 		)
 		return
 	}
-	r.client = client
+	r.client = data.client
+	r.data = data
+}
+
+func (r *domainPurchaseResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	r.data.planCustomer(ctx, req, resp)
 }
 
 func (r *domainPurchaseResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -174,14 +182,20 @@ func (r *domainPurchaseResource) Create(ctx context.Context, req resource.Create
 		return
 	}
 
-	tflog.Info(ctx, "purchasing domain", map[string]any{"domain": plan.Domain.ValueString()})
-	if _, err := r.client.PurchaseDomain(plan.Customer.ValueString(), purchase); err != nil {
-		resp.Diagnostics.AddError("Failed to purchase domain", err.Error())
+	environment := r.client.Environment()
+	tflog.Info(ctx, "purchasing domain", map[string]any{"domain": plan.Domain.ValueString(), "environment": environment})
+	if _, err := r.client.PurchaseDomain(ctx, plan.Customer.ValueString(), purchase); err != nil {
+		addAPIError(&resp.Diagnostics, fmt.Sprintf("Failed to purchase domain in the %s environment", environment), err, purchaseFieldPath)
 		return
 	}
 
-	if d := r.fetchAndPopulate(ctx, &plan); d.HasError() {
-		resp.Diagnostics.Append(d...)
+	domainInfo, err := lookupDomain(ctx, r.client, plan.Customer.ValueString(), plan.Domain.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Couldn't find domain", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(populateDomain(ctx, &plan, domainInfo)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
@@ -194,8 +208,18 @@ func (r *domainPurchaseResource) Read(ctx context.Context, req resource.ReadRequ
 		return
 	}
 
-	if d := r.fetchAndPopulate(ctx, &state); d.HasError() {
-		resp.Diagnostics.Append(d...)
+	d, err := r.client.GetDomain(ctx, state.Customer.ValueString(), state.Domain.ValueString())
+	if api.IsNotFound(err) || (err == nil && d.Status == api.StatusCancelled) {
+		tflog.Warn(ctx, "domain no longer registered, removing from state", map[string]any{"domain": state.Domain.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Couldn't read domain", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(populateDomain(ctx, &state, d)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
@@ -214,14 +238,26 @@ func (r *domainPurchaseResource) Update(ctx context.Context, req resource.Update
 		return
 	}
 
+	unlock, err := lockDomain(ctx, plan.Domain.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Cancelled while waiting for other changes to the domain", err.Error())
+		return
+	}
+	defer unlock()
+
 	tflog.Info(ctx, "updating domain", map[string]any{"domain": plan.Domain.ValueString()})
-	if err := r.client.UpdateDomain(plan.Customer.ValueString(), plan.Domain.ValueString(), purchase); err != nil {
-		resp.Diagnostics.AddError("Failed to update domain", err.Error())
+	if err := r.client.UpdateDomain(ctx, plan.Customer.ValueString(), plan.Domain.ValueString(), purchase); err != nil {
+		addAPIError(&resp.Diagnostics, "Failed to update domain", err, purchaseFieldPath)
 		return
 	}
 
-	if d := r.fetchAndPopulate(ctx, &plan); d.HasError() {
-		resp.Diagnostics.Append(d...)
+	domainInfo, err := r.client.GetDomain(ctx, plan.Customer.ValueString(), plan.Domain.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Couldn't read domain", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(populateDomain(ctx, &plan, domainInfo)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
@@ -234,23 +270,27 @@ func (r *domainPurchaseResource) Delete(ctx context.Context, req resource.Delete
 		return
 	}
 
+	unlock, err := lockDomain(ctx, state.Domain.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Cancelled while waiting for other changes to the domain", err.Error())
+		return
+	}
+	defer unlock()
+
 	tflog.Info(ctx, "canceling domain", map[string]any{"domain": state.Domain.ValueString()})
-	if err := r.client.CancelDomain(state.Customer.ValueString(), state.Domain.ValueString()); err != nil {
+	if err := r.client.CancelDomain(ctx, state.Customer.ValueString(), state.Domain.ValueString()); err != nil {
 		resp.Diagnostics.AddError("Failed to cancel domain", err.Error())
 	}
 }
 
 func (r *domainPurchaseResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("domain"), req.ID)...)
+	r.data.importCustomer(ctx, resp)
 }
 
-func (r *domainPurchaseResource) fetchAndPopulate(ctx context.Context, state *domainPurchaseResourceModel) diag.Diagnostics {
+// populateDomain copies the fetched domain details onto the model.
+func populateDomain(ctx context.Context, state *domainPurchaseResourceModel, d *api.Domain) diag.Diagnostics {
 	var diags diag.Diagnostics
-	d, err := lookupDomain(r.client, state.Customer.ValueString(), state.Domain.ValueString())
-	if err != nil {
-		diags.AddError("Couldn't find domain", err.Error())
-		return diags
-	}
 	state.ID = types.StringValue(strconv.FormatInt(d.ID, 10))
 	state.AutoRenew = types.BoolValue(d.AutoRenew)
 	state.EnablePrivacy = types.BoolValue(d.EnablePrivacy)
@@ -286,9 +326,9 @@ func planToPurchase(_ context.Context, plan *domainPurchaseResourceModel) (*api.
 		if diags.HasError() {
 			return nil, diags
 		}
-		for _, n := range ns {
+		for i, n := range ns {
 			if err := api.ValidateData(api.NSType, n); err != nil {
-				diags.AddError("Invalid nameserver", err.Error())
+				diags.AddAttributeError(path.Root("nameservers").AtListIndex(i), "Invalid nameserver", err.Error())
 				return nil, diags
 			}
 		}
@@ -305,6 +345,77 @@ func planToPurchase(_ context.Context, plan *domainPurchaseResourceModel) (*api.
 	return purchase, diags
 }
 
+var purchaseAttributes = map[string]string{
+	"domain":    "domain",
+	"period":    "years_leased",
+	"privacy":   "enable_privacy",
+	"renewAuto": "auto_renew",
+}
+
+var contactBlocks = map[string]string{
+	"contactAdmin":      "admin",
+	"contactBilling":    "billing",
+	"contactRegistrant": "registrant",
+	"contactTech":       "tech",
+}
+
+var contactAttributes = map[string]string{
+	"email":        "email",
+	"fax":          "fax",
+	"jobTitle":     "job_title",
+	"nameFirst":    "first_name",
+	"nameLast":     "last_name",
+	"nameMiddle":   "middle_name",
+	"organization": "organization",
+	"phone":        "phone",
+}
+
+var addressAttributes = map[string]string{
+	"address1":   "line_1",
+	"address2":   "line_2",
+	"city":       "city",
+	"country":    "country",
+	"postalCode": "postal_code",
+	"state":      "state",
+}
+
+// purchaseFieldPath maps a GoDaddy DomainPurchase field path, e.g.
+// "contactRegistrant.addressMailing.postalCode", onto the resource schema.
+// Unrecognised trailing segments resolve to the closest known parent.
+func purchaseFieldPath(steps []fieldStep) (path.Path, bool) {
+	if len(steps) == 0 {
+		return path.Empty(), false
+	}
+	if attr, ok := purchaseAttributes[steps[0].name]; ok {
+		return path.Root(attr), true
+	}
+	if steps[0].name == "nameServers" {
+		return nameserversFieldPath(steps)
+	}
+
+	block, ok := contactBlocks[steps[0].name]
+	if !ok {
+		return path.Empty(), false
+	}
+	p := path.Root(block)
+	if len(steps) == 1 {
+		return p, true
+	}
+	if steps[1].name == "addressMailing" {
+		p = p.AtName("address")
+		if len(steps) > 2 {
+			if attr, ok := addressAttributes[steps[2].name]; ok {
+				p = p.AtName(attr)
+			}
+		}
+		return p, true
+	}
+	if attr, ok := contactAttributes[steps[1].name]; ok {
+		p = p.AtName(attr)
+	}
+	return p, true
+}
+
 func contactToAPI(c *contactModel) *api.Contact {
 	if c == nil {
 		return nil
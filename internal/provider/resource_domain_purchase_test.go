@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CruGlobal/terraform-provider-godaddy/internal/api"
+)
+
+func TestDomainPurchaseRead(t *testing.T) {
+	var criteria = []struct {
+		Name         string
+		Status       int
+		DomainStatus string
+		Removed      bool
+		Negative     bool
+	}{
+		{"Given an active domain", http.StatusOK, api.StatusActive, false, false},
+		{"Given a cancelled domain", http.StatusOK, api.StatusCancelled, true, false},
+		{"Given a domain that no longer exists", http.StatusNotFound, "", true, false},
+		{"Given a server error", http.StatusInternalServerError, "", false, true},
+		{"Given a forbidden domain", http.StatusForbidden, "", false, true},
+	}
+	for _, test := range criteria {
+		t.Run(test.Name, func(t *testing.T) {
+			client := domainServer(t, test.Status, test.DomainStatus)
+			r := &domainPurchaseResource{client: client, data: &providerData{client: client}}
+			state := domainState(t, r)
+
+			resp := resource.ReadResponse{State: state}
+			r.Read(context.Background(), resource.ReadRequest{State: state}, &resp)
+			assert.Equal(t, test.Negative, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+			assert.Equal(t, test.Removed, resp.State.Raw.IsNull())
+			if test.Negative {
+				assert.True(t, state.Raw.Equal(resp.State.Raw), "state changed to %s", resp.State.Raw)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -12,10 +15,12 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 
 	"github.com/CruGlobal/terraform-provider-godaddy/internal/api"
@@ -26,10 +31,19 @@ var defaultRecords = []*api.DomainRecord{
 	{Type: api.CNameType, Name: "_domainconnect", Data: "_domainconnect.gd.domaincontrol.com", TTL: api.DefaultTTL},
 }
 
+// What godaddy_domain_record does with the domain's records when destroyed.
+const (
+	onDestroyRestoreDefaults   = "restore_defaults"
+	onDestroyRemoveManagedOnly = "remove_managed_only"
+	onDestroyRetain            = "retain"
+)
+
 var (
-	_ resource.Resource                = &domainRecordResource{}
-	_ resource.ResourceWithConfigure   = &domainRecordResource{}
-	_ resource.ResourceWithImportState = &domainRecordResource{}
+	_ resource.Resource                 = &domainRecordResource{}
+	_ resource.ResourceWithConfigure    = &domainRecordResource{}
+	_ resource.ResourceWithImportState  = &domainRecordResource{}
+	_ resource.ResourceWithModifyPlan   = &domainRecordResource{}
+	_ resource.ResourceWithUpgradeState = &domainRecordResource{}
 )
 
 func NewDomainRecordResource() resource.Resource {
@@ -38,43 +52,51 @@ func NewDomainRecordResource() resource.Resource {
 
 type domainRecordResource struct {
 	client *api.Client
+	data   *providerData
 }
 
 type domainRecordResourceModel struct {
-	ID          types.String `tfsdk:"id"`
-	Domain      types.String `tfsdk:"domain"`
-	Customer    types.String `tfsdk:"customer"`
-	Addresses   types.List   `tfsdk:"addresses"`
-	Nameservers types.List   `tfsdk:"nameservers"`
-	Record      types.Set    `tfsdk:"record"`
+	ID             types.String `tfsdk:"id"`
+	Domain         types.String `tfsdk:"domain"`
+	Customer       types.String `tfsdk:"customer"`
+	Authoritative  types.Bool   `tfsdk:"authoritative"`
+	Subdomain      types.String `tfsdk:"subdomain"`
+	ManagedTypes   types.Set    `tfsdk:"managed_types"`
+	AdoptExisting  types.Bool   `tfsdk:"adopt_existing"`
+	Adopted        types.Set    `tfsdk:"adopted_records"`
+	OnDestroy      types.String `tfsdk:"on_destroy"`
+	DefaultRecords types.Set    `tfsdk:"default_records"`
+	Addresses      types.List   `tfsdk:"addresses"`
+	Nameservers    types.List   `tfsdk:"nameservers"`
+	Record         types.Set    `tfsdk:"record"`
+}
+
+// authoritative reports whether the resource owns the whole zone. Imported
+// resources have no value until their first read and default to true.
+func (m *domainRecordResourceModel) authoritative() bool {
+	return m.Authoritative.IsNull() || m.Authoritative.IsUnknown() || m.Authoritative.ValueBool()
 }
 
 type recordModel struct {
-	Name     types.String `tfsdk:"name"`
-	Type     types.String `tfsdk:"type"`
-	Data     types.String `tfsdk:"data"`
-	TTL      types.Int64  `tfsdk:"ttl"`
-	Priority types.Int64  `tfsdk:"priority"`
-	Weight   types.Int64  `tfsdk:"weight"`
-	Service  types.String `tfsdk:"service"`
-	Protocol types.String `tfsdk:"protocol"`
-	Port     types.Int64  `tfsdk:"port"`
+	Name types.String `tfsdk:"name"`
+	Type types.String `tfsdk:"type"`
+	TTL  types.Int64  `tfsdk:"ttl"`
+	Data types.String `tfsdk:"data"`
+	MX   types.Object `tfsdk:"mx"`
+	SRV  types.Object `tfsdk:"srv"`
+	CAA  types.Object `tfsdk:"caa"`
+}
+
+func (m *recordModel) value() recordValue {
+	return recordValue{Data: m.Data, MX: m.MX, SRV: m.SRV, CAA: m.CAA}
 }
 
 func recordObjectType() types.ObjectType {
-	return types.ObjectType{
-		AttrTypes: map[string]attr.Type{
-			"name":     types.StringType,
-			"type":     types.StringType,
-			"data":     types.StringType,
-			"ttl":      types.Int64Type,
-			"priority": types.Int64Type,
-			"weight":   types.Int64Type,
-			"service":  types.StringType,
-			"protocol": types.StringType,
-			"port":     types.Int64Type,
-		},
-	}
+	attrTypes := recordValueAttrTypes()
+	attrTypes["name"] = types.StringType
+	attrTypes["type"] = types.StringType
+	attrTypes["ttl"] = types.Int64Type
+	return types.ObjectType{AttrTypes: attrTypes}
 }
 
 func (r *domainRecordResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -84,6 +106,9 @@ func (r *domainRecordResource) Metadata(_ context.Context, req resource.Metadata
 func (r *domainRecordResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "`godaddy_domain_record` manages DNS records for a domain registered with GoDaddy.",
+		// Version 1 moved the MX, SRV and CAA fields of records into mx, srv
+		// and caa blocks; see UpgradeState.
+		Version: 1,
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Description: "Numeric GoDaddy domain ID.",
@@ -100,8 +125,35 @@ func (r *domainRecordResource) Schema(_ context.Context, _ resource.SchemaReques
 				},
 			},
 			"customer": schema.StringAttribute{
-				Description: "Optional GoDaddy customer (shopper) ID. Required when the API key does not belong to the customer owning the domain.",
+				Description: "GoDaddy customer (shopper) ID. Required when the API key does not belong to the customer owning the domain. Defaults to the provider's `customer`.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"authoritative": schema.BoolAttribute{
+				Description: "Whether the resource owns the whole zone. When `true`, records missing from the configuration are removed, and by default destroying the resource restores `default_records`. When `false`, only the records in the configuration are created, updated and deleted; other records on the domain are left alone and ignored on read. Defaults to `true`.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+			},
+			"subdomain": schema.StringAttribute{
+				Description: "Limits the resource to records named `subdomain` or ending in `.subdomain`, e.g. `dev` covers `dev` and `api.dev`. Records outside it are left alone and ignored on read, and may not be configured.",
+				Optional:    true,
+			},
+			"managed_types": schema.SetAttribute{
+				Description: "Limits the resource to records of these types, e.g. `[\"MX\", \"TXT\"]`. Records of other types are left alone and ignored on read, and may not be configured.",
 				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"adopt_existing": schema.BoolAttribute{
+				Description: "When creating the resource, keep the records already on the domain that the configuration doesn't cover instead of deleting them. They are listed in `adopted_records` and left alone from then on; add one to `record` to manage it. Has no effect after creation. Defaults to `false`.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"adopted_records": schema.SetNestedAttribute{
+				Description:  "Records that were on the domain when the resource was created with `adopt_existing`, and that it leaves alone.",
+				Computed:     true,
+				NestedObject: adoptedRecordNestedObject(),
 			},
 			"addresses": schema.ListAttribute{
 				Description: "A records pointing the root (`@`) of the domain at the given IP addresses.",
@@ -115,77 +167,143 @@ func (r *domainRecordResource) Schema(_ context.Context, _ resource.SchemaReques
 				ElementType: types.StringType,
 			},
 			"record": schema.SetNestedAttribute{
-				Description: "One or more DNS records to manage on the domain.",
+				Description:  "One or more DNS records to manage on the domain.",
+				Optional:     true,
+				Computed:     true,
+				NestedObject: recordNestedObject(),
+			},
+			"on_destroy": schema.StringAttribute{
+				Description: "What to do with the domain's records when the resource is destroyed. `restore_defaults` replaces the records the resource manages with `default_records`; `remove_managed_only` deletes only the records in its configuration; `retain` leaves every record in place. Defaults to `restore_defaults` when `authoritative` and to `remove_managed_only` otherwise.",
 				Optional:    true,
-				Computed:    true,
-				NestedObject: schema.NestedAttributeObject{
-					Attributes: map[string]schema.Attribute{
-						"name": schema.StringAttribute{
-							Description: "Record name (subdomain). Use `@` for the root.",
-							Required:    true,
-						},
-						"type": schema.StringAttribute{
-							Description: "Record type. One of A, AAAA, CAA, CNAME, MX, NS, SOA, SRV, TXT.",
-							Required:    true,
-						},
-						"data": schema.StringAttribute{
-							Description: "Record data (value).",
-							Required:    true,
-						},
-						"ttl": schema.Int64Attribute{
-							Description: "Record TTL in seconds.",
-							Optional:    true,
-							Computed:    true,
-							Default:     int64default.StaticInt64(int64(api.DefaultTTL)),
-						},
-						"priority": schema.Int64Attribute{
-							Description: "Priority (MX records).",
-							Optional:    true,
-							Computed:    true,
-							Default:     int64default.StaticInt64(int64(api.DefaultPriority)),
-						},
-						"weight": schema.Int64Attribute{
-							Description: "Weight (SRV records).",
-							Optional:    true,
-							Computed:    true,
-							Default:     int64default.StaticInt64(int64(api.DefaultWeight)),
-						},
-						"service": schema.StringAttribute{
-							Description: "Service (SRV records). Must start with an underscore.",
-							Optional:    true,
-							Computed:    true,
-						},
-						"protocol": schema.StringAttribute{
-							Description: "Protocol (SRV records). Must start with an underscore.",
-							Optional:    true,
-							Computed:    true,
-						},
-						"port": schema.Int64Attribute{
-							Description: "Port (SRV records).",
-							Optional:    true,
-							Computed:    true,
-							Default:     int64default.StaticInt64(int64(api.DefaultPort)),
-						},
-					},
-				},
+			},
+			"default_records": schema.SetNestedAttribute{
+				Description:  "The records written when the resource is destroyed with `on_destroy = \"restore_defaults\"`. Defaults to GoDaddy's own: a `www` CNAME to `@` and a `_domainconnect` CNAME. Set to `[]` to restore nothing.",
+				Optional:     true,
+				NestedObject: recordNestedObject(),
 			},
 		},
 	}
 }
 
+// recordNestedObject is the schema of a record in the record set and in
+// default_records. Each record's value goes in data or in the mx, srv or caa
+// block for its type; the others stay null, so they never show in a plan.
+func recordNestedObject() schema.NestedAttributeObject {
+	attrs := recordValueAttributes()
+	attrs["name"] = schema.StringAttribute{
+		Description: "Record name (subdomain). Use `@` for the root.",
+		Required:    true,
+	}
+	attrs["type"] = schema.StringAttribute{
+		Description: "Record type. One of A, AAAA, CAA, CNAME, MX, NS, SOA, SRV, TXT.",
+		Required:    true,
+	}
+	attrs["ttl"] = schema.Int64Attribute{
+		Description: "Record TTL in seconds.",
+		Optional:    true,
+		Computed:    true,
+		Default:     int64default.StaticInt64(int64(api.DefaultTTL)),
+	}
+	return schema.NestedAttributeObject{Attributes: attrs}
+}
+
+// adoptedRecordNestedObject is the schema of a record in adopted_records.
+func adoptedRecordNestedObject() schema.NestedAttributeObject {
+	attrs := computedRecordValueAttributes()
+	attrs["name"] = schema.StringAttribute{Description: "Record name (subdomain).", Computed: true}
+	attrs["type"] = schema.StringAttribute{Description: "Record type.", Computed: true}
+	attrs["ttl"] = schema.Int64Attribute{Description: "Record TTL in seconds.", Computed: true}
+	return schema.NestedAttributeObject{Attributes: attrs}
+}
+
 func (r *domainRecordResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
 		return
 	}
-	client, ok := req.ProviderData.(*api.Client)
+	data, ok := req.ProviderData.(*providerData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected provider data",
-			fmt.Sprintf("Expected *api.Client, got %T. Please report this to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *providerData, got %T. Please report this to the provider developers.", req.ProviderData),
 		)
 		return
 	}
-	r.client = client
+	r.client = data.client
+	r.data = data
+}
+
+// ModifyPlan validates the destroy settings and the scope of the configured
+// records, and previews what the plan does to the records already on the
+// domain: on create it warns about any it deletes or, with adopt_existing,
+// plans to keep them, and on update it drops the adopted records that are now
+// configured.
+func (r *domainRecordResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	r.data.planCustomer(ctx, req, resp)
+	if resp.Diagnostics.HasError() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan domainRecordResourceModel
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// Mistakes in the destroy settings would otherwise only surface when
+	// the resource is destroyed.
+	resp.Diagnostics.Append(plan.validateOnDestroy(ctx)...)
+	resp.Diagnostics.Append(plan.validateScope(ctx)...)
+	if resp.Diagnostics.HasError() || r.client == nil || !req.Config.Raw.IsFullyKnown() || plan.Customer.IsUnknown() {
+		return
+	}
+
+	var adopted []*api.DomainRecord
+	if !req.State.Raw.IsNull() {
+		var state domainRecordResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		var d diag.Diagnostics
+		adopted, d = stillAdopted(ctx, &state, &plan)
+		resp.Diagnostics.Append(d...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	} else {
+		removed, d, err := r.recordsToRemove(ctx, &plan)
+		resp.Diagnostics.Append(d...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if err != nil {
+			// The domain may be registered in the same apply.
+			if !api.IsNotFound(err) {
+				resp.Diagnostics.AddWarning("Couldn't preview record changes", err.Error())
+			}
+			return
+		}
+		switch {
+		case plan.AdoptExisting.ValueBool():
+			adopted = removed
+		case len(removed) > 0:
+			lines := make([]string, 0, len(removed))
+			for _, rec := range removed {
+				lines = append(lines, "  - "+describeRecord(rec))
+			}
+			resp.Diagnostics.AddWarning(
+				"Existing records will be deleted",
+				fmt.Sprintf("%s has %d records that the configuration doesn't cover, and creating this resource will delete them:\n%s\n\nAdd them to the configuration, or set adopt_existing = true to keep them.",
+					plan.Domain.ValueString(), len(removed), strings.Join(lines, "\n")),
+			)
+		}
+	}
+
+	adoptedSet, d := recordsToSet(adopted)
+	resp.Diagnostics.Append(d...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("adopted_records"), adoptedSet)...)
 }
 
 func (r *domainRecordResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -195,7 +313,31 @@ func (r *domainRecordResource) Create(ctx context.Context, req resource.CreateRe
 		return
 	}
 
-	resp.Diagnostics.Append(r.applyPlan(ctx, &plan)...)
+	// adopted_records is only unknown here if it couldn't be planned, such as
+	// when the domain didn't exist yet.
+	if plan.Adopted.IsUnknown() {
+		adopted := []*api.DomainRecord{}
+		if plan.AdoptExisting.ValueBool() {
+			removed, d, err := r.recordsToRemove(ctx, &plan)
+			resp.Diagnostics.Append(d...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+			if err != nil {
+				resp.Diagnostics.AddError("Couldn't read domain records", err.Error())
+				return
+			}
+			adopted = removed
+		}
+		adoptedSet, d := recordsToSet(adopted)
+		resp.Diagnostics.Append(d...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		plan.Adopted = adoptedSet
+	}
+
+	resp.Diagnostics.Append(r.applyPlan(ctx, &plan, nil)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -213,6 +355,20 @@ func (r *domainRecordResource) Read(ctx context.Context, req resource.ReadReques
 		return
 	}
 
+	domainInfo, err := r.client.GetDomain(ctx, state.Customer.ValueString(), state.Domain.ValueString())
+	if api.IsNotFound(err) {
+		tflog.Warn(ctx, "domain no longer exists, removing from state", map[string]any{"domain": state.Domain.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Couldn't find domain", err.Error())
+		return
+	}
+	state.ID = types.StringValue(strconv.FormatInt(domainInfo.ID, 10))
+	state.Authoritative = types.BoolValue(state.authoritative())
+	state.AdoptExisting = types.BoolValue(state.AdoptExisting.ValueBool())
+
 	resp.Diagnostics.Append(r.refreshState(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -221,13 +377,26 @@ func (r *domainRecordResource) Read(ctx context.Context, req resource.ReadReques
 }
 
 func (r *domainRecordResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan domainRecordResourceModel
+	var plan, state domainRecordResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	resp.Diagnostics.Append(r.applyPlan(ctx, &plan)...)
+	adopted, d := stillAdopted(ctx, &state, &plan)
+	resp.Diagnostics.Append(d...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	adoptedSet, d := recordsToSet(adopted)
+	resp.Diagnostics.Append(d...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.Adopted = adoptedSet
+
+	resp.Diagnostics.Append(r.applyPlan(ctx, &plan, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -247,62 +416,375 @@ func (r *domainRecordResource) Delete(ctx context.Context, req resource.DeleteRe
 
 	customer := state.Customer.ValueString()
 	domain := state.Domain.ValueString()
+	scope, d := state.scope(ctx)
+	resp.Diagnostics.Append(d...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	switch state.onDestroy() {
+	case onDestroyRetain:
+		tflog.Info(ctx, "leaving DNS records in place", map[string]any{"domain": domain})
+		return
+	case onDestroyRemoveManagedOnly:
+		owned, _, d := buildRecords(ctx, &state)
+		resp.Diagnostics.Append(d...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		tflog.Info(ctx, "removing managed DNS records", map[string]any{"domain": domain, "records": len(owned)})
+		if _, err := r.data.syncRecords(ctx, customer, domain, recordUpdate{desired: []*api.DomainRecord{}, managed: scope.filter(recordsMatching(owned))}); err != nil {
+			addAPIError(&resp.Diagnostics, "Failed to remove records", err, nil)
+		}
+		return
+	}
+
+	defaults, _, d := state.restoredRecords(ctx)
+	resp.Diagnostics.Append(d...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	managed, d := state.managedFilter(ctx, defaults, &state)
+	resp.Diagnostics.Append(d...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	tflog.Info(ctx, "restoring default DNS records", map[string]any{"domain": domain})
-	if err := r.client.UpdateDomainRecords(customer, domain, defaultRecords); err != nil {
-		resp.Diagnostics.AddError("Failed to restore default records", err.Error())
+	tflog.Info(ctx, "restoring default DNS records", map[string]any{"domain": domain, "records": len(defaults)})
+	if _, err := r.data.syncRecords(ctx, customer, domain, recordUpdate{desired: defaults, managed: managed}); err != nil {
+		addAPIError(&resp.Diagnostics, "Failed to restore default records", err, nil)
 	}
 }
 
 func (r *domainRecordResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("domain"), req.ID)...)
+	r.data.importCustomer(ctx, resp)
 }
 
 // applyPlan converts the plan into API records and pushes them to GoDaddy.
-func (r *domainRecordResource) applyPlan(ctx context.Context, plan *domainRecordResourceModel) diag.Diagnostics {
+// prior is the state being updated, or nil on create. Only the records
+// selected by managedFilter are touched.
+func (r *domainRecordResource) applyPlan(ctx context.Context, plan, prior *domainRecordResourceModel) diag.Diagnostics {
 	var diags diag.Diagnostics
 	customer := plan.Customer.ValueString()
 	domain := plan.Domain.ValueString()
 
-	domainInfo, err := lookupDomain(r.client, customer, domain)
+	domainInfo, err := lookupDomain(ctx, r.client, customer, domain)
 	if err != nil {
 		diags.AddError("Couldn't find domain", err.Error())
 		return diags
 	}
 	plan.ID = types.StringValue(strconv.FormatInt(domainInfo.ID, 10))
 
-	records, d := buildRecords(ctx, plan)
+	records, sources, d := buildRecords(ctx, plan)
 	diags.Append(d...)
 	if diags.HasError() {
 		return diags
 	}
 
-	tflog.Info(ctx, "updating domain records", map[string]any{"domain": domain})
-	if err := r.client.UpdateDomainRecords(customer, domain, records); err != nil {
-		diags.AddError("Failed to update records", err.Error())
+	managed, d := plan.managedFilter(ctx, records, prior)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	tflog.Info(ctx, "updating domain records", map[string]any{"domain": domain, "authoritative": plan.authoritative()})
+	changes, err := r.data.syncRecords(ctx, customer, domain, recordUpdate{desired: records, managed: managed})
+	if changes != nil {
+		tflog.Info(ctx, "domain record changes", map[string]any{"domain": domain, "changes": changes.String()})
+	}
+	if err != nil {
+		addAPIError(&diags, "Failed to update records", err, sources.fieldPath(err))
+		var rollbackErr *api.RecordRollbackError
+		if errors.As(err, &rollbackErr) {
+			addRollbackResult(&diags, rollbackErr)
+		}
+	}
+	return diags
+}
+
+// managedFilter selects the records on the domain that applying records
+// may change: those within the resource's scope, other than adopted ones.
+// When the resource isn't authoritative, that is further limited to records
+// in records or in prior, so that records dropped from the configuration are
+// deleted and nothing else is. prior is the state being updated, or nil.
+func (m *domainRecordResourceModel) managedFilter(ctx context.Context, records []*api.DomainRecord, prior *domainRecordResourceModel) (api.RecordFilter, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	scope, d := m.scope(ctx)
+	diags.Append(d...)
+	adopted, d := setRecords(ctx, m.Adopted)
+	diags.Append(d...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	var managed api.RecordFilter
+	if !m.authoritative() {
+		owned := records
+		if prior != nil {
+			priorRecords, _, d := buildRecords(ctx, prior)
+			diags.Append(d...)
+			if diags.HasError() {
+				return nil, diags
+			}
+			owned = append(priorRecords, records...)
+		}
+		managed = recordsMatching(owned)
+	}
+	managed = scope.filter(managed)
+
+	if len(adopted) > 0 {
+		isAdopted, inner := recordsMatching(adopted), managed
+		managed = func(rec *api.DomainRecord) bool {
+			return !isAdopted(rec) && (inner == nil || inner(rec))
+		}
+	}
+	return managed, diags
+}
+
+// onDestroy returns the on_destroy mode, falling back to the default for
+// the resource's authoritative setting.
+func (m *domainRecordResourceModel) onDestroy() string {
+	if !m.OnDestroy.IsNull() && !m.OnDestroy.IsUnknown() {
+		return m.OnDestroy.ValueString()
+	}
+	if m.authoritative() {
+		return onDestroyRestoreDefaults
+	}
+	return onDestroyRemoveManagedOnly
+}
+
+// validateOnDestroy checks on_destroy and default_records.
+func (m *domainRecordResourceModel) validateOnDestroy(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if m.OnDestroy.IsUnknown() || m.DefaultRecords.IsUnknown() {
+		return diags
+	}
+
+	mode := m.onDestroy()
+	switch mode {
+	case onDestroyRestoreDefaults, onDestroyRemoveManagedOnly, onDestroyRetain:
+	default:
+		diags.AddAttributeError(path.Root("on_destroy"), "Invalid on_destroy",
+			fmt.Sprintf("on_destroy must be one of %s, %s or %s; got %q.", onDestroyRestoreDefaults, onDestroyRemoveManagedOnly, onDestroyRetain, mode))
+		return diags
+	}
+
+	if m.DefaultRecords.IsNull() {
+		return diags
+	}
+	_, _, d := m.restoredRecords(ctx)
+	diags.Append(d...)
+	if !diags.HasError() && mode != onDestroyRestoreDefaults {
+		diags.AddAttributeWarning(path.Root("default_records"), "default_records is unused",
+			fmt.Sprintf("default_records are only written when on_destroy is %s, but it is %s.", onDestroyRestoreDefaults, mode))
 	}
 	return diags
 }
 
-// refreshState fetches current records from GoDaddy and stores them on the model.
+// validateScope checks that the records the plan configures, including
+// default_records, are within the resource's scope. Records whose name or
+// type isn't known yet are checked once it is.
+func (m *domainRecordResourceModel) validateScope(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if m.Subdomain.IsUnknown() || m.ManagedTypes.IsUnknown() {
+		return diags
+	}
+	scope, d := m.scope(ctx)
+	diags.Append(d...)
+	if diags.HasError() || scope.empty() {
+		return diags
+	}
+
+	check := func(p path.Path, t, name types.String, verb string) {
+		if t.IsUnknown() || name.IsUnknown() {
+			return
+		}
+		if !scope.contains(&api.DomainRecord{Type: t.ValueString(), Name: name.ValueString()}) {
+			diags.AddAttributeError(p, "Record outside the managed scope",
+				fmt.Sprintf("This resource only manages %s, so it can't %s the %s record %q.", scope, verb, t.ValueString(), name.ValueString()))
+		}
+	}
+	sets := []struct {
+		attr string
+		verb string
+		set  types.Set
+	}{
+		{"record", "manage", m.Record},
+		{"default_records", "restore", m.DefaultRecords},
+	}
+	for _, s := range sets {
+		if s.set.IsNull() || s.set.IsUnknown() {
+			continue
+		}
+		for _, elem := range s.set.Elements() {
+			obj, ok := elem.(types.Object)
+			if !ok || obj.IsUnknown() {
+				continue
+			}
+			t, _ := obj.Attributes()["type"].(types.String)
+			name, _ := obj.Attributes()["name"].(types.String)
+			check(path.Root(s.attr).AtSetValue(elem), t, name, s.verb)
+		}
+	}
+
+	// addresses and nameservers are written as records at the root.
+	lists := []struct {
+		attr       string
+		recordType string
+		list       types.List
+	}{
+		{"addresses", api.AType, m.Addresses},
+		{"nameservers", api.NSType, m.Nameservers},
+	}
+	for _, l := range lists {
+		if l.list.IsNull() || l.list.IsUnknown() {
+			continue
+		}
+		for i := range l.list.Elements() {
+			check(path.Root(l.attr).AtListIndex(i), types.StringValue(l.recordType), types.StringValue(api.Ptr), "manage")
+		}
+	}
+	return diags
+}
+
+// restoredRecords returns the records that destroying the resource with
+// restore_defaults writes: default_records, or GoDaddy's defaults within the
+// resource's scope.
+func (m *domainRecordResourceModel) restoredRecords(ctx context.Context) ([]*api.DomainRecord, recordSources, diag.Diagnostics) {
+	if m.DefaultRecords.IsNull() || m.DefaultRecords.IsUnknown() {
+		scope, diags := m.scope(ctx)
+		return scope.records(defaultRecords), recordSources{}, diags
+	}
+	sources := recordSources{}
+	records, diags := buildRecordSet(ctx, m.DefaultRecords, "default_records", sources)
+	return records, sources, diags
+}
+
+// recordsToRemove reads the domain's records and returns those that
+// applying plan would delete. Records the plan merely changes, such as a new
+// TTL, aren't included. API failures are returned as an error, so that
+// callers can tell a missing domain apart.
+func (r *domainRecordResource) recordsToRemove(ctx context.Context, plan *domainRecordResourceModel) ([]*api.DomainRecord, diag.Diagnostics, error) {
+	var diags diag.Diagnostics
+	records, _, d := buildRecords(ctx, plan)
+	diags.Append(d...)
+	if diags.HasError() {
+		return nil, diags, nil
+	}
+	managed, d := plan.managedFilter(ctx, records, nil)
+	diags.Append(d...)
+	if diags.HasError() {
+		return nil, diags, nil
+	}
+
+	current, err := r.client.GetDomainRecords(ctx, plan.Customer.ValueString(), plan.Domain.ValueString())
+	if err != nil {
+		return nil, diags, err
+	}
+
+	configured := recordsMatching(records)
+	removed := []*api.DomainRecord{}
+	for _, rec := range api.DiffRecords(current, records, managed).Removed() {
+		if !configured(rec) {
+			removed = append(removed, rec)
+		}
+	}
+	return removed, diags, nil
+}
+
+// stillAdopted returns the records of prior's adopted_records that plan
+// doesn't configure. Once configured, a record is managed like any other.
+func stillAdopted(ctx context.Context, prior, plan *domainRecordResourceModel) ([]*api.DomainRecord, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	adopted, d := setRecords(ctx, prior.Adopted)
+	diags.Append(d...)
+	records, _, d := buildRecords(ctx, plan)
+	diags.Append(d...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	configured := recordsMatching(records)
+	remaining := []*api.DomainRecord{}
+	for _, rec := range adopted {
+		if !configured(rec) {
+			remaining = append(remaining, rec)
+		}
+	}
+	return remaining, diags
+}
+
+func describeRecord(rec *api.DomainRecord) string {
+	return fmt.Sprintf("%s %s %s", rec.Type, rec.Name, rec.Data)
+}
+
+// refreshState fetches current records from GoDaddy and stores them on the
+// model. Callers are expected to have already looked up the domain and set ID.
 func (r *domainRecordResource) refreshState(ctx context.Context, state *domainRecordResourceModel) diag.Diagnostics {
 	var diags diag.Diagnostics
 	customer := state.Customer.ValueString()
 	domain := state.Domain.ValueString()
 
-	domainInfo, err := lookupDomain(r.client, customer, domain)
+	tflog.Info(ctx, "fetching domain records", map[string]any{"domain": domain})
+	records, err := r.client.GetDomainRecords(ctx, customer, domain)
 	if err != nil {
-		diags.AddError("Couldn't find domain", err.Error())
+		diags.AddError("Couldn't read domain records", err.Error())
 		return diags
 	}
-	state.ID = types.StringValue(strconv.FormatInt(domainInfo.ID, 10))
 
-	tflog.Info(ctx, "fetching domain records", map[string]any{"domain": domain})
-	records, err := r.client.GetDomainRecords(customer, domain)
-	if err != nil {
-		diags.AddError("Couldn't read domain records", err.Error())
+	// Records outside the resource's scope are ignored. When the resource
+	// isn't authoritative, only the records it already knows about are its
+	// own; everything else on the domain is ignored too.
+	scope, d := state.scope(ctx)
+	diags.Append(d...)
+	if diags.HasError() {
 		return diags
 	}
+	var managed api.RecordFilter
+	if !state.authoritative() {
+		known, _, d := buildRecords(ctx, state)
+		diags.Append(d...)
+		if diags.HasError() {
+			return diags
+		}
+		managed = recordsMatching(known)
+	}
+	if managed = scope.filter(managed); managed != nil {
+		owned := make([]*api.DomainRecord, 0, len(records))
+		for _, rec := range records {
+			if managed(rec) {
+				owned = append(owned, rec)
+			}
+		}
+		records = owned
+	}
+
+	// Adopted records that still exist stay adopted, and are kept out of the
+	// managed records.
+	adopted, d := setRecords(ctx, state.Adopted)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+	isAdopted := recordsMatching(adopted)
+	stillThere := []*api.DomainRecord{}
+	owned := make([]*api.DomainRecord, 0, len(records))
+	for _, rec := range records {
+		if isAdopted(rec) {
+			stillThere = append(stillThere, rec)
+		} else {
+			owned = append(owned, rec)
+		}
+	}
+	records = owned
+	adoptedSet, d := recordsToSet(stillThere)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+	state.Adopted = adoptedSet
 
 	// If the user has nameservers in state, we treat the default NS records as
 	// managed; otherwise we leave them alone (GoDaddy's defaults).
@@ -348,54 +830,173 @@ func (r *domainRecordResource) refreshState(ctx context.Context, state *domainRe
 	return diags
 }
 
-func buildRecords(ctx context.Context, plan *domainRecordResourceModel) ([]*api.DomainRecord, diag.Diagnostics) {
+// recordScope is the part of a zone a godaddy_domain_record manages: records
+// named subdomain or below it, of one of types. Empty fields don't limit the
+// scope, so the zero value covers the whole zone.
+type recordScope struct {
+	subdomain string
+	types     []string
+}
+
+// scope reads the resource's subdomain and managed_types.
+func (m *domainRecordResourceModel) scope(ctx context.Context) (recordScope, diag.Diagnostics) {
 	var diags diag.Diagnostics
-	out := []*api.DomainRecord{}
+	var scope recordScope
 
-	if !plan.Record.IsNull() && !plan.Record.IsUnknown() {
-		var recs []recordModel
-		diags.Append(plan.Record.ElementsAs(ctx, &recs, false)...)
+	if !m.Subdomain.IsNull() && !m.Subdomain.IsUnknown() {
+		sub := strings.ToLower(strings.TrimSpace(m.Subdomain.ValueString()))
+		if sub == "" || sub == "@" || strings.HasPrefix(sub, ".") || strings.HasSuffix(sub, ".") {
+			diags.AddAttributeError(path.Root("subdomain"), "Invalid subdomain",
+				fmt.Sprintf("%q is not a subdomain; give a name relative to the domain such as \"dev\", or leave subdomain unset to manage the whole zone.", m.Subdomain.ValueString()))
+			return scope, diags
+		}
+		scope.subdomain = sub
+	}
+
+	if !m.ManagedTypes.IsNull() && !m.ManagedTypes.IsUnknown() {
+		diags.Append(m.ManagedTypes.ElementsAs(ctx, &scope.types, false)...)
 		if diags.HasError() {
-			return nil, diags
+			return scope, diags
 		}
-		for _, rec := range recs {
-			built, err := api.NewDomainRecord(
-				rec.Name.ValueString(),
-				rec.Type.ValueString(),
-				rec.Data.ValueString(),
-				int(rec.TTL.ValueInt64()),
-				api.Priority(int(rec.Priority.ValueInt64())),
-				api.Weight(int(rec.Weight.ValueInt64())),
-				api.Port(int(rec.Port.ValueInt64())),
-				api.Service(rec.Service.ValueString()),
-				api.Protocol(rec.Protocol.ValueString()),
-			)
-			if err != nil {
-				diags.AddError("Invalid record", err.Error())
-				return nil, diags
+		for i, t := range scope.types {
+			scope.types[i] = strings.ToUpper(strings.TrimSpace(t))
+			if !api.IsSupportedType(scope.types[i]) {
+				diags.AddAttributeError(path.Root("managed_types"), "Invalid record type", fmt.Sprintf("%q is not a supported record type.", t))
+				return scope, diags
 			}
-			out = append(out, built)
 		}
+		sort.Strings(scope.types)
+	}
+	return scope, diags
+}
+
+func (s recordScope) empty() bool {
+	return s.subdomain == "" && len(s.types) == 0
+}
+
+// contains reports whether rec is within the scope.
+func (s recordScope) contains(rec *api.DomainRecord) bool {
+	if s.subdomain != "" {
+		name := strings.ToLower(rec.Name)
+		if name != s.subdomain && !strings.HasSuffix(name, "."+s.subdomain) {
+			return false
+		}
+	}
+	if len(s.types) > 0 && !slices.Contains(s.types, strings.ToUpper(rec.Type)) {
+		return false
+	}
+	return true
+}
+
+// filter narrows managed to the records within the scope. A nil managed
+// selects every record in the scope, and the result is nil when neither
+// limits anything.
+func (s recordScope) filter(managed api.RecordFilter) api.RecordFilter {
+	if s.empty() {
+		return managed
+	}
+	return func(rec *api.DomainRecord) bool {
+		return s.contains(rec) && (managed == nil || managed(rec))
+	}
+}
+
+// records returns the records within the scope.
+func (s recordScope) records(records []*api.DomainRecord) []*api.DomainRecord {
+	out := make([]*api.DomainRecord, 0, len(records))
+	for _, rec := range records {
+		if s.contains(rec) {
+			out = append(out, rec)
+		}
+	}
+	return out
+}
+
+func (s recordScope) String() string {
+	var parts []string
+	if s.subdomain != "" {
+		parts = append(parts, fmt.Sprintf("records named %q or below it", s.subdomain))
+	}
+	if len(s.types) > 0 {
+		parts = append(parts, strings.Join(s.types, ", ")+" records")
+	}
+	if len(parts) == 0 {
+		return "all records"
+	}
+	return strings.Join(parts, " and ")
+}
+
+// recordsMatching returns a filter that selects records with the same
+// recordKey as one of records, whatever their other attributes.
+func recordsMatching(records []*api.DomainRecord) api.RecordFilter {
+	keys := make(map[string]bool, len(records))
+	for _, rec := range records {
+		keys[recordKey(rec)] = true
+	}
+	return func(rec *api.DomainRecord) bool {
+		return keys[recordKey(rec)]
+	}
+}
+
+// recordKey identifies a record by its type, name and data. A name has at
+// most one CNAME or SOA record, so those are identified by type and name
+// alone: when one is changed outside Terraform it is still the same record,
+// to be replaced rather than left next to the configured one. CAA data is
+// compared by its fields, however GoDaddy quotes the value.
+func recordKey(rec *api.DomainRecord) string {
+	key := strings.ToUpper(rec.Type) + "/" + strings.ToLower(rec.Name)
+	switch strings.ToUpper(rec.Type) {
+	case api.CNameType, api.SOAType:
+		return key
+	case api.CAAType:
+		if flags, tag, value, err := api.ParseCAAData(rec.Data); err == nil {
+			return key + "/" + api.FormatCAAData(flags, tag, value)
+		}
+	}
+	return key + "/" + rec.Data
+}
+
+// recordSource is the configuration a record was built from. nested is set
+// for record set elements, whose individual attributes can be addressed, and
+// root is set when their name, type and ttl are attributes of the resource
+// instead, as for godaddy_dns_record.
+type recordSource struct {
+	path   path.Path
+	nested bool
+	root   bool
+}
+
+// recordSources maps each record produced by buildRecords back to the
+// configuration it came from, so that API errors can point at it.
+type recordSources map[*api.DomainRecord]recordSource
+
+func buildRecords(ctx context.Context, plan *domainRecordResourceModel) ([]*api.DomainRecord, recordSources, diag.Diagnostics) {
+	sources := recordSources{}
+
+	out, diags := buildRecordSet(ctx, plan.Record, "record", sources)
+	if diags.HasError() {
+		return nil, nil, diags
 	}
 
 	if !plan.Nameservers.IsNull() && !plan.Nameservers.IsUnknown() {
 		var ns []string
 		diags.Append(plan.Nameservers.ElementsAs(ctx, &ns, false)...)
 		if diags.HasError() {
-			return nil, diags
+			return nil, nil, diags
 		}
-		for _, n := range ns {
+		for i, n := range ns {
+			elemPath := path.Root("nameservers").AtListIndex(i)
 			n = strings.TrimSpace(n)
 			if err := api.ValidateData(api.NSType, n); err != nil {
-				diags.AddError("Invalid nameserver", err.Error())
-				return nil, diags
+				diags.AddAttributeError(elemPath, "Invalid nameserver", err.Error())
+				return nil, nil, diags
 			}
 			rec, err := api.NewNSRecord(n)
 			if err != nil {
-				diags.AddError("Invalid nameserver", err.Error())
-				return nil, diags
+				diags.AddAttributeError(elemPath, "Invalid nameserver", err.Error())
+				return nil, nil, diags
 			}
 			out = append(out, rec)
+			sources[rec] = recordSource{path: elemPath}
 		}
 	}
 
@@ -403,66 +1004,146 @@ func buildRecords(ctx context.Context, plan *domainRecordResourceModel) ([]*api.
 		var addrs []string
 		diags.Append(plan.Addresses.ElementsAs(ctx, &addrs, false)...)
 		if diags.HasError() {
-			return nil, diags
+			return nil, nil, diags
 		}
-		for _, a := range addrs {
+		for i, a := range addrs {
+			elemPath := path.Root("addresses").AtListIndex(i)
 			if err := api.ValidateData(api.AType, a); err != nil {
-				diags.AddError("Invalid address", err.Error())
-				return nil, diags
+				diags.AddAttributeError(elemPath, "Invalid address", err.Error())
+				return nil, nil, diags
 			}
 			rec, err := api.NewARecord(a)
 			if err != nil {
-				diags.AddError("Invalid address", err.Error())
-				return nil, diags
+				diags.AddAttributeError(elemPath, "Invalid address", err.Error())
+				return nil, nil, diags
 			}
 			out = append(out, rec)
+			sources[rec] = recordSource{path: elemPath}
 		}
 	}
 
+	return out, sources, diags
+}
+
+// buildRecordSet validates and converts the records of the set attribute
+// attr, noting where each came from in sources.
+func buildRecordSet(ctx context.Context, set types.Set, attr string, sources recordSources) ([]*api.DomainRecord, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	out := []*api.DomainRecord{}
+	if set.IsNull() || set.IsUnknown() {
+		return out, diags
+	}
+
+	for _, elem := range set.Elements() {
+		elemPath := path.Root(attr).AtSetValue(elem)
+		obj, ok := elem.(types.Object)
+		if !ok {
+			diags.AddAttributeError(elemPath, "Invalid record", fmt.Sprintf("unexpected record value %T", elem))
+			return nil, diags
+		}
+		var rec recordModel
+		diags.Append(obj.As(ctx, &rec, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		built, d := rec.value().build(ctx, elemPath, rec.Name.ValueString(), rec.Type.ValueString(), int(rec.TTL.ValueInt64()))
+		diags.Append(d...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		out = append(out, built)
+		sources[built] = recordSource{path: elemPath, nested: true}
+	}
 	return out, diags
 }
 
-func recordsToSet(recs []*api.DomainRecord) (types.Set, diag.Diagnostics) {
+// fieldPath returns a mapper that resolves a GoDaddy field path such as
+// "records[2].data" against the records of a failed write. Records from the
+// record set resolve to the offending attribute; A and NS records built from
+// addresses and nameservers resolve to the list element.
+func (s recordSources) fieldPath(err error) fieldPathMapper {
+	var writeErr *api.RecordWriteError
+	if !errors.As(err, &writeErr) {
+		return nil
+	}
+	return func(steps []fieldStep) (path.Path, bool) {
+		for i, step := range steps {
+			if step.index < 0 || step.index >= len(writeErr.Records) {
+				continue
+			}
+			src, ok := s[writeErr.Records[step.index]]
+			if !ok {
+				return path.Empty(), false
+			}
+			if src.nested && len(steps) > i+1 {
+				switch field := steps[i+1].name; field {
+				case "name", "ttl", "type":
+					if src.root {
+						return path.Root(field), true
+					}
+					return src.path.AtName(field), true
+				default:
+					if p, ok := recordFieldPath(src.path, writeErr.Records[step.index].Type, field); ok {
+						return p, true
+					}
+				}
+			}
+			return src.path, true
+		}
+		return path.Empty(), false
+	}
+}
+
+// setRecords converts a set of records read from GoDaddy, such as
+// adopted_records, back into API records without validating them.
+func setRecords(ctx context.Context, set types.Set) ([]*api.DomainRecord, diag.Diagnostics) {
 	var diags diag.Diagnostics
-	objs := make([]attr.Value, 0, len(recs))
-	for _, r := range recs {
-		port := int64(0)
-		if r.Port != nil {
-			port = int64(*r.Port)
-		}
-		obj, d := types.ObjectValue(recordObjectType().AttrTypes, map[string]attr.Value{
-			"name":     types.StringValue(r.Name),
-			"type":     types.StringValue(r.Type),
-			"data":     types.StringValue(r.Data),
-			"ttl":      types.Int64Value(int64(r.TTL)),
-			"priority": types.Int64Value(int64(r.Priority)),
-			"weight":   types.Int64Value(int64(r.Weight)),
-			"service":  types.StringValue(r.Service),
-			"protocol": types.StringValue(r.Protocol),
-			"port":     types.Int64Value(port),
-		})
+	if set.IsNull() || set.IsUnknown() {
+		return nil, diags
+	}
+	var models []recordModel
+	diags.Append(set.ElementsAs(ctx, &models, false)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	out := make([]*api.DomainRecord, 0, len(models))
+	for _, m := range models {
+		rec, d := m.value().record(ctx, m.Name.ValueString(), m.Type.ValueString(), int(m.TTL.ValueInt64()))
 		diags.Append(d...)
 		if diags.HasError() {
-			return types.SetNull(recordObjectType()), diags
+			return nil, diags
 		}
-		objs = append(objs, obj)
+		out = append(out, rec)
 	}
-	set, d := types.SetValue(recordObjectType(), objs)
-	diags.Append(d...)
-	return set, diags
+	return out, diags
+}
+
+func recordsToSet(recs []*api.DomainRecord) (types.Set, diag.Diagnostics) {
+	return recordValueSet(recs, recordObjectType(), func(r *api.DomainRecord) map[string]attr.Value {
+		return map[string]attr.Value{
+			"name": types.StringValue(r.Name),
+			"type": types.StringValue(r.Type),
+			"ttl":  types.Int64Value(int64(r.TTL)),
+		}
+	})
 }
 
 // lookupDomain retries fetching the domain a few times — GoDaddy returns 404
-// while domain registrations propagate.
-func lookupDomain(client *api.Client, customer, domain string) (*api.Domain, error) {
+// while domain registrations propagate. It gives up early if ctx is cancelled.
+func lookupDomain(ctx context.Context, client *api.Client, customer, domain string) (*api.Domain, error) {
 	var err error
 	var d *api.Domain
 	for i := 0; i < 10; i++ {
-		d, err = client.GetDomain(customer, domain)
-		if err == nil {
-			return d, nil
+		d, err = client.GetDomain(ctx, customer, domain)
+		if err == nil || !api.IsNotFound(err) {
+			return d, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
 		}
-		time.Sleep(5 * time.Second)
 	}
 	return nil, err
 }
@@ -0,0 +1,471 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CruGlobal/terraform-provider-godaddy/internal/api"
+)
+
+func TestRecordsMatching(t *testing.T) {
+	managed := recordsMatching([]*api.DomainRecord{
+		{Type: api.TXTType, Name: "_dmarc", Data: "v=DMARC1; p=reject", TTL: 600},
+		{Type: api.CNameType, Name: "www", Data: "example.github.io", TTL: api.DefaultTTL},
+		{Type: api.CAAType, Name: "@", Data: `0 issue "letsencrypt.org"`, TTL: api.DefaultTTL},
+	})
+
+	var criteria = []struct {
+		Name     string
+		Record   *api.DomainRecord
+		Expected bool
+	}{
+		{"Given the same record", &api.DomainRecord{Type: api.TXTType, Name: "_dmarc", Data: "v=DMARC1; p=reject", TTL: 600}, true},
+		{"Given a different TTL", &api.DomainRecord{Type: api.TXTType, Name: "_DMARC", Data: "v=DMARC1; p=reject", TTL: api.DefaultTTL}, true},
+		{"Given different data", &api.DomainRecord{Type: api.TXTType, Name: "_dmarc", Data: "v=DMARC1; p=none", TTL: 600}, false},
+		{"Given another name", &api.DomainRecord{Type: api.TXTType, Name: "_acme-challenge", Data: "v=DMARC1; p=reject", TTL: 600}, false},
+		{"Given a CNAME with different data", &api.DomainRecord{Type: api.CNameType, Name: "WWW", Data: "other.example.com", TTL: api.DefaultTTL}, true},
+		{"Given CAA data quoted differently", &api.DomainRecord{Type: api.CAAType, Name: "@", Data: "0 issue letsencrypt.org", TTL: api.DefaultTTL}, true},
+		{"Given a CAA record for another authority", &api.DomainRecord{Type: api.CAAType, Name: "@", Data: `0 issue "pki.goog"`, TTL: api.DefaultTTL}, false},
+		{"Given a CNAME with another name", &api.DomainRecord{Type: api.CNameType, Name: "blog", Data: "example.github.io", TTL: api.DefaultTTL}, false},
+	}
+	for _, test := range criteria {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, managed(test.Record))
+		})
+	}
+}
+
+func TestRecordScope(t *testing.T) {
+	scope := recordScope{subdomain: "dev", types: []string{api.AType, api.TXTType}}
+
+	var criteria = []struct {
+		Name     string
+		Record   *api.DomainRecord
+		Expected bool
+	}{
+		{"Given the subdomain itself", &api.DomainRecord{Type: api.AType, Name: "dev", Data: "192.0.2.1"}, true},
+		{"Given a name below the subdomain", &api.DomainRecord{Type: "txt", Name: "_acme-challenge.API.dev", Data: "token"}, true},
+		{"Given a name that only ends like the subdomain", &api.DomainRecord{Type: api.AType, Name: "webdev", Data: "192.0.2.1"}, false},
+		{"Given the root", &api.DomainRecord{Type: api.AType, Name: "@", Data: "192.0.2.1"}, false},
+		{"Given another type", &api.DomainRecord{Type: api.CNameType, Name: "www.dev", Data: "@"}, false},
+	}
+	for _, test := range criteria {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, scope.contains(test.Record))
+			assert.Equal(t, test.Expected, scope.filter(nil)(test.Record))
+		})
+	}
+
+	assert.Nil(t, recordScope{}.filter(nil), "an empty scope shouldn't filter anything")
+}
+
+func TestValidateScope(t *testing.T) {
+	records := func(recs ...*api.DomainRecord) types.Set {
+		set, d := recordsToSet(recs)
+		assert.False(t, d.HasError())
+		return set
+	}
+	inScope := &api.DomainRecord{Type: api.TXTType, Name: "_acme-challenge.dev", Data: "token", TTL: api.DefaultTTL}
+	outside := &api.DomainRecord{Type: api.TXTType, Name: "@", Data: "v=spf1 -all", TTL: api.DefaultTTL}
+	unknownName := types.SetValueMust(recordObjectType(), []attr.Value{
+		types.ObjectValueMust(recordObjectType().AttrTypes, map[string]attr.Value{
+			"name": types.StringUnknown(),
+			"type": types.StringValue(api.TXTType),
+			"ttl":  types.Int64Value(api.DefaultTTL),
+			"data": types.StringValue("token"),
+			"mx":   types.ObjectNull(mxObjectType().AttrTypes),
+			"srv":  types.ObjectNull(srvObjectType().AttrTypes),
+			"caa":  types.ObjectNull(caaObjectType().AttrTypes),
+		}),
+	})
+	noAddresses := types.ListNull(types.StringType)
+
+	var criteria = []struct {
+		Name      string
+		Record    types.Set
+		Defaults  types.Set
+		Addresses types.List
+		Negative  bool
+	}{
+		{"Given records in scope", records(inScope), types.SetNull(recordObjectType()), noAddresses, false},
+		{"Given a record outside the scope", records(inScope, outside), types.SetNull(recordObjectType()), noAddresses, true},
+		{"Given a default record outside the scope", records(inScope), records(outside), noAddresses, true},
+		{"Given a record whose name is unknown", unknownName, types.SetNull(recordObjectType()), noAddresses, false},
+		{"Given an unknown record set", types.SetUnknown(recordObjectType()), types.SetNull(recordObjectType()), noAddresses, false},
+		{"Given addresses at the root", records(inScope), types.SetNull(recordObjectType()),
+			types.ListValueMust(types.StringType, []attr.Value{types.StringValue("192.0.2.1")}), true},
+	}
+	for _, test := range criteria {
+		t.Run(test.Name, func(t *testing.T) {
+			m := &domainRecordResourceModel{
+				Subdomain:      types.StringValue("dev"),
+				ManagedTypes:   types.SetNull(types.StringType),
+				Record:         test.Record,
+				DefaultRecords: test.Defaults,
+				Addresses:      test.Addresses,
+				Nameservers:    types.ListNull(types.StringType),
+			}
+			assert.Equal(t, test.Negative, m.validateScope(context.Background()).HasError())
+		})
+	}
+}
+
+func TestRecordsToRemove(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]*api.DomainRecord{
+			{Type: api.CNameType, Name: "www", Data: "@", TTL: api.DefaultTTL},
+			{Type: api.TXTType, Name: "@", Data: "v=spf1 -all", TTL: api.DefaultTTL},
+			{Type: api.MXType, Name: "@", Data: "mx.example.com", TTL: api.DefaultTTL, Priority: 10},
+		})
+	}))
+	defer srv.Close()
+
+	client, err := api.NewClient(srv.URL, "preview-key", "secret", api.WithRateLimit(6000, false))
+	assert.Nil(t, err)
+	r := &domainRecordResource{client: client}
+
+	configured, d := recordsToSet([]*api.DomainRecord{
+		// A new TTL replaces the record rather than removing it.
+		{Type: api.CNameType, Name: "www", Data: "@", TTL: 600},
+	})
+	assert.False(t, d.HasError())
+	plan := &domainRecordResourceModel{
+		Domain:        types.StringValue("example.com"),
+		Customer:      types.StringNull(),
+		Authoritative: types.BoolValue(true),
+		Subdomain:     types.StringNull(),
+		ManagedTypes:  types.SetNull(types.StringType),
+		Adopted:       types.SetUnknown(recordObjectType()),
+		Addresses:     types.ListNull(types.StringType),
+		Nameservers:   types.ListNull(types.StringType),
+		Record:        configured,
+	}
+
+	removed, d, err := r.recordsToRemove(context.Background(), plan)
+	assert.Nil(t, err)
+	assert.False(t, d.HasError())
+	var described []string
+	for _, rec := range removed {
+		described = append(described, describeRecord(rec))
+	}
+	assert.ElementsMatch(t, []string{"TXT @ v=spf1 -all", "MX @ mx.example.com"}, described)
+
+	// Records outside the scope are never removed.
+	plan.ManagedTypes, d = types.SetValueFrom(context.Background(), types.StringType, []string{api.CNameType, api.TXTType})
+	assert.False(t, d.HasError())
+	removed, _, err = r.recordsToRemove(context.Background(), plan)
+	assert.Nil(t, err)
+	assert.Len(t, removed, 1)
+}
+
+func TestOnDestroy(t *testing.T) {
+	var criteria = []struct {
+		Name          string
+		Authoritative types.Bool
+		OnDestroy     types.String
+		Expected      string
+		Negative      bool
+	}{
+		{"Given an authoritative resource", types.BoolValue(true), types.StringNull(), onDestroyRestoreDefaults, false},
+		{"Given a non-authoritative resource", types.BoolValue(false), types.StringNull(), onDestroyRemoveManagedOnly, false},
+		{"Given an explicit mode", types.BoolValue(true), types.StringValue(onDestroyRetain), onDestroyRetain, false},
+		{"Given an unknown mode", types.BoolValue(true), types.StringValue("delete_everything"), "delete_everything", true},
+	}
+	for _, test := range criteria {
+		t.Run(test.Name, func(t *testing.T) {
+			m := &domainRecordResourceModel{
+				Authoritative:  test.Authoritative,
+				OnDestroy:      test.OnDestroy,
+				DefaultRecords: types.SetNull(recordObjectType()),
+			}
+			assert.Equal(t, test.Expected, m.onDestroy())
+			assert.Equal(t, test.Negative, m.validateOnDestroy(context.Background()).HasError())
+		})
+	}
+}
+
+func TestRestoredRecords(t *testing.T) {
+	m := &domainRecordResourceModel{
+		Subdomain:      types.StringNull(),
+		ManagedTypes:   types.SetNull(types.StringType),
+		DefaultRecords: types.SetNull(recordObjectType()),
+	}
+	restored, _, d := m.restoredRecords(context.Background())
+	assert.False(t, d.HasError())
+	assert.Equal(t, defaultRecords, restored)
+
+	// GoDaddy's defaults are limited to the resource's scope.
+	m.ManagedTypes, d = types.SetValueFrom(context.Background(), types.StringType, []string{api.TXTType})
+	assert.False(t, d.HasError())
+	restored, _, d = m.restoredRecords(context.Background())
+	assert.False(t, d.HasError())
+	assert.Empty(t, restored)
+
+	m.DefaultRecords, d = recordsToSet([]*api.DomainRecord{{Type: api.TXTType, Name: "@", Data: "v=spf1 -all", TTL: api.DefaultTTL}})
+	assert.False(t, d.HasError())
+	restored, _, d = m.restoredRecords(context.Background())
+	assert.False(t, d.HasError())
+	assert.Len(t, restored, 1)
+}
+
+func TestUpgradeRecordSetV0(t *testing.T) {
+	ctx := context.Background()
+	objType := domainRecordSchemaV0().Attributes["record"].(schema.SetNestedAttribute).NestedObject.Type().(types.ObjectType)
+	record := func(t, data string, priority int64) attr.Value {
+		return types.ObjectValueMust(objType.AttrTypes, map[string]attr.Value{
+			"name":     types.StringValue("@"),
+			"type":     types.StringValue(t),
+			"data":     types.StringValue(data),
+			"ttl":      types.Int64Value(api.DefaultTTL),
+			"priority": types.Int64Value(priority),
+			"weight":   types.Int64Value(0),
+			"service":  types.StringValue(""),
+			"protocol": types.StringValue(""),
+			"port":     types.Int64Value(0),
+		})
+	}
+	prior := types.SetValueMust(objType, []attr.Value{
+		record(api.MXType, "mx.example.com", 10),
+		record(api.TXTType, "v=spf1 -all", 0),
+		record(api.CAAType, `0 issue "letsencrypt.org"`, 0),
+	})
+
+	upgraded, d := upgradeRecordSetV0(ctx, prior)
+	assert.False(t, d.HasError())
+	expected, d := recordsToSet([]*api.DomainRecord{
+		{Type: api.MXType, Name: "@", Data: "mx.example.com", Priority: 10, TTL: api.DefaultTTL},
+		{Type: api.TXTType, Name: "@", Data: "v=spf1 -all", TTL: api.DefaultTTL},
+		{Type: api.CAAType, Name: "@", Data: `0 issue "letsencrypt.org"`, TTL: api.DefaultTTL},
+	})
+	assert.False(t, d.HasError())
+	assert.True(t, expected.Equal(upgraded), "got %s", upgraded)
+
+	upgraded, d = upgradeRecordSetV0(ctx, types.SetNull(objType))
+	assert.False(t, d.HasError())
+	assert.True(t, upgraded.IsNull())
+}
+
+func TestDomainRecordUpgradeStateV0(t *testing.T) {
+	ctx := context.Background()
+	r := &domainRecordResource{}
+	upgrader := r.UpgradeState(ctx)[0]
+
+	// State as written by the last release with schema version 0.
+	raw := tfprotov6.RawState{JSON: []byte(`{
+		"id": "1234",
+		"domain": "example.com",
+		"customer": null,
+		"addresses": ["192.0.2.1"],
+		"nameservers": null,
+		"record": [
+			{"name": "@", "type": "A", "data": "192.0.2.1", "ttl": 600, "priority": 0, "weight": 0, "service": "", "protocol": "", "port": 0},
+			{"name": "@", "type": "MX", "data": "mx.example.com", "ttl": 600, "priority": 10, "weight": 0, "service": "", "protocol": "", "port": 0},
+			{"name": "@", "type": "SRV", "data": "sip.example.com", "ttl": 600, "priority": 1, "weight": 5, "service": "_sip", "protocol": "_tcp", "port": 5060}
+		]
+	}`)}
+	prior, err := raw.Unmarshal(upgrader.PriorSchema.Type().TerraformType(ctx))
+	assert.Nil(t, err)
+
+	var current resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &current)
+	req := resource.UpgradeStateRequest{State: &tfsdk.State{Schema: *upgrader.PriorSchema, Raw: prior}}
+	resp := resource.UpgradeStateResponse{State: tfsdk.State{Schema: current.Schema}}
+	upgrader.StateUpgrader(ctx, req, &resp)
+	assert.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+	var state domainRecordResourceModel
+	assert.False(t, resp.State.Get(ctx, &state).HasError())
+	assert.Equal(t, "1234", state.ID.ValueString())
+	assert.True(t, state.Customer.IsNull())
+	assert.True(t, state.Authoritative.ValueBool())
+	assert.False(t, state.AdoptExisting.ValueBool())
+	assert.True(t, state.Subdomain.IsNull())
+	assert.True(t, state.ManagedTypes.IsNull())
+	assert.True(t, state.OnDestroy.IsNull())
+	assert.True(t, state.DefaultRecords.IsNull())
+	assert.Empty(t, state.Adopted.Elements())
+
+	port := 5060
+	expected, d := recordsToSet([]*api.DomainRecord{
+		{Type: api.AType, Name: "@", Data: "192.0.2.1", TTL: 600},
+		{Type: api.MXType, Name: "@", Data: "mx.example.com", Priority: 10, TTL: 600},
+		{Type: api.SRVType, Name: "@", Data: "sip.example.com", Priority: 1, Weight: 5, Service: "_sip", Protocol: "_tcp", Port: &port, TTL: 600},
+	})
+	assert.False(t, d.HasError())
+	assert.True(t, expected.Equal(state.Record), "got %s", state.Record)
+}
+
+// fakeZone is a GoDaddy API serving a single domain's records, for tests that
+// read and write them.
+type fakeZone struct {
+	mu      sync.Mutex
+	domain  string
+	records []*api.DomainRecord
+}
+
+func newFakeZone(t *testing.T, domain string, records ...*api.DomainRecord) (*fakeZone, *api.Client) {
+	zone := &fakeZone{domain: domain, records: records}
+	srv := httptest.NewServer(zone)
+	t.Cleanup(srv.Close)
+	client, err := api.NewClient(srv.URL, "zone-key-"+t.Name(), "secret", api.WithRateLimit(6000, false))
+	assert.Nil(t, err)
+	return zone, client
+}
+
+// ServeHTTP handles /v1/domains/{domain}[/records[/{type}[/{name}]]].
+func (z *fakeZone) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/domains/"), "/")
+	if parts[0] != z.domain {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"code": "NOT_FOUND", "message": "domain not found"})
+		return
+	}
+	if len(parts) == 1 {
+		json.NewEncoder(w).Encode(api.Domain{ID: 1, Name: z.domain})
+		return
+	}
+
+	var recordType, name string
+	if len(parts) > 2 {
+		recordType = parts[2]
+	}
+	if len(parts) > 3 {
+		name = parts[3]
+	}
+	selected := func(rec *api.DomainRecord) bool {
+		return (recordType == "" || rec.Type == recordType) && (name == "" || rec.Name == name)
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		matched := []*api.DomainRecord{}
+		if r.URL.Query().Get("offset") == "0" {
+			for _, rec := range z.records {
+				if selected(rec) {
+					matched = append(matched, rec)
+				}
+			}
+		}
+		json.NewEncoder(w).Encode(matched)
+	case http.MethodPut, http.MethodDelete:
+		var written []*api.DomainRecord
+		if r.Method == http.MethodPut {
+			if err := json.NewDecoder(r.Body).Decode(&written); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		kept := []*api.DomainRecord{}
+		for _, rec := range z.records {
+			if !selected(rec) {
+				kept = append(kept, rec)
+			}
+		}
+		if r.Method == http.MethodDelete && len(kept) == len(z.records) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"code": "NOT_FOUND", "message": "no records"})
+			return
+		}
+		for _, rec := range written {
+			if rec.Type == "" {
+				rec.Type = recordType
+			}
+		}
+		z.records = append(kept, written...)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// ofType returns the zone's records of type t.
+func (z *fakeZone) ofType(t string) []string {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	var out []string
+	for _, rec := range z.records {
+		if rec.Type == t {
+			out = append(out, describeRecord(rec))
+		}
+	}
+	return out
+}
+
+func (z *fakeZone) set(records ...*api.DomainRecord) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.records = records
+}
+
+func TestNonAuthoritativeApply(t *testing.T) {
+	ctx := context.Background()
+	spf := &api.DomainRecord{Type: api.TXTType, Name: "@", Data: "v=spf1 -all", TTL: api.DefaultTTL}
+	zone, client := newFakeZone(t, "example.com",
+		&api.DomainRecord{Type: api.CNameType, Name: "www", Data: "@", TTL: api.DefaultTTL},
+		spf,
+	)
+	r := &domainRecordResource{client: client, data: &providerData{client: client}}
+
+	configured, d := recordsToSet([]*api.DomainRecord{
+		{Type: api.CNameType, Name: "www", Data: "example.github.io", TTL: api.DefaultTTL},
+	})
+	assert.False(t, d.HasError())
+	plan := domainRecordResourceModel{
+		Domain:         types.StringValue("example.com"),
+		Customer:       types.StringNull(),
+		Authoritative:  types.BoolValue(false),
+		Subdomain:      types.StringNull(),
+		ManagedTypes:   types.SetNull(types.StringType),
+		AdoptExisting:  types.BoolValue(false),
+		Adopted:        types.SetValueMust(recordObjectType(), nil),
+		OnDestroy:      types.StringNull(),
+		DefaultRecords: types.SetNull(recordObjectType()),
+		Addresses:      types.ListNull(types.StringType),
+		Nameservers:    types.ListNull(types.StringType),
+		Record:         configured,
+	}
+
+	state := plan
+	assert.False(t, r.applyPlan(ctx, &state, nil).HasError())
+	assert.False(t, r.refreshState(ctx, &state).HasError())
+	assert.Equal(t, []string{"CNAME www example.github.io"}, zone.ofType(api.CNameType))
+	assert.Equal(t, []string{"TXT @ v=spf1 -all"}, zone.ofType(api.TXTType))
+	assert.True(t, configured.Equal(state.Record), "got %s", state.Record)
+
+	// A record changed outside Terraform is still the resource's own: it is
+	// read back as drift, and applying replaces it rather than adding a
+	// second CNAME next to it.
+	zone.set(&api.DomainRecord{Type: api.CNameType, Name: "www", Data: "other.example.com", TTL: api.DefaultTTL}, spf)
+	assert.False(t, r.refreshState(ctx, &state).HasError())
+	drifted, d := recordsToSet([]*api.DomainRecord{
+		{Type: api.CNameType, Name: "www", Data: "other.example.com", TTL: api.DefaultTTL},
+	})
+	assert.False(t, d.HasError())
+	assert.True(t, drifted.Equal(state.Record), "got %s", state.Record)
+
+	updated := plan
+	assert.False(t, r.applyPlan(ctx, &updated, &state).HasError())
+	assert.Equal(t, []string{"CNAME www example.github.io"}, zone.ofType(api.CNameType))
+	assert.Equal(t, []string{"TXT @ v=spf1 -all"}, zone.ofType(api.TXTType))
+
+	// Records the resource doesn't own are never read into state.
+	zone.set(&api.DomainRecord{Type: api.CNameType, Name: "www", Data: "example.github.io", TTL: api.DefaultTTL}, spf,
+		&api.DomainRecord{Type: api.TXTType, Name: "_dmarc", Data: "v=DMARC1; p=none", TTL: api.DefaultTTL})
+	assert.False(t, r.refreshState(ctx, &updated).HasError())
+	assert.True(t, configured.Equal(updated.Record), "got %s", updated.Record)
+}
@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/CruGlobal/terraform-provider-godaddy/internal/api"
+)
+
+// Version 0 of godaddy_domain_record, the last released one, gave every
+// record flat priority, weight, service, protocol and port attributes,
+// whatever its type, and had none of the scoping and on_destroy attributes.
+// The schema and models below are frozen copies of it, only used to read old
+// state.
+
+type domainRecordResourceModelV0 struct {
+	ID          types.String `tfsdk:"id"`
+	Domain      types.String `tfsdk:"domain"`
+	Customer    types.String `tfsdk:"customer"`
+	Addresses   types.List   `tfsdk:"addresses"`
+	Nameservers types.List   `tfsdk:"nameservers"`
+	Record      types.Set    `tfsdk:"record"`
+}
+
+type recordModelV0 struct {
+	Name     types.String `tfsdk:"name"`
+	Type     types.String `tfsdk:"type"`
+	Data     types.String `tfsdk:"data"`
+	TTL      types.Int64  `tfsdk:"ttl"`
+	Priority types.Int64  `tfsdk:"priority"`
+	Weight   types.Int64  `tfsdk:"weight"`
+	Service  types.String `tfsdk:"service"`
+	Protocol types.String `tfsdk:"protocol"`
+	Port     types.Int64  `tfsdk:"port"`
+}
+
+func domainRecordSchemaV0() schema.Schema {
+	return schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id":          schema.StringAttribute{Computed: true},
+			"domain":      schema.StringAttribute{Required: true},
+			"customer":    schema.StringAttribute{Optional: true},
+			"addresses":   schema.ListAttribute{Optional: true, Computed: true, ElementType: types.StringType},
+			"nameservers": schema.ListAttribute{Optional: true, ElementType: types.StringType},
+			"record": schema.SetNestedAttribute{
+				Optional: true,
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name":     schema.StringAttribute{Required: true},
+						"type":     schema.StringAttribute{Required: true},
+						"data":     schema.StringAttribute{Required: true},
+						"ttl":      schema.Int64Attribute{Optional: true, Computed: true},
+						"priority": schema.Int64Attribute{Optional: true, Computed: true},
+						"weight":   schema.Int64Attribute{Optional: true, Computed: true},
+						"service":  schema.StringAttribute{Optional: true, Computed: true},
+						"protocol": schema.StringAttribute{Optional: true, Computed: true},
+						"port":     schema.Int64Attribute{Optional: true, Computed: true},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *domainRecordResource) UpgradeState(_ context.Context) map[int64]resource.StateUpgrader {
+	schemaV0 := domainRecordSchemaV0()
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema: &schemaV0,
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				var prior domainRecordResourceModelV0
+				resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+				state, diags := upgradeDomainRecordStateV0(ctx, &prior)
+				resp.Diagnostics.Append(diags...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+				resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+			},
+		},
+	}
+}
+
+// upgradeDomainRecordStateV0 moves the fields of version 0 records into the
+// block for their type. The attributes added since behave as they did then:
+// the resource owns the whole zone and has adopted nothing.
+func upgradeDomainRecordStateV0(ctx context.Context, prior *domainRecordResourceModelV0) (*domainRecordResourceModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	state := &domainRecordResourceModel{
+		ID:             prior.ID,
+		Domain:         prior.Domain,
+		Customer:       prior.Customer,
+		Authoritative:  types.BoolValue(true),
+		Subdomain:      types.StringNull(),
+		ManagedTypes:   types.SetNull(types.StringType),
+		AdoptExisting:  types.BoolValue(false),
+		OnDestroy:      types.StringNull(),
+		DefaultRecords: types.SetNull(recordObjectType()),
+		Addresses:      prior.Addresses,
+		Nameservers:    prior.Nameservers,
+	}
+
+	var d diag.Diagnostics
+	state.Record, d = upgradeRecordSetV0(ctx, prior.Record)
+	diags.Append(d...)
+	state.Adopted, d = recordsToSet(nil)
+	diags.Append(d...)
+	return state, diags
+}
+
+func upgradeRecordSetV0(ctx context.Context, set types.Set) (types.Set, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if set.IsNull() {
+		return types.SetNull(recordObjectType()), diags
+	}
+	if set.IsUnknown() {
+		return types.SetUnknown(recordObjectType()), diags
+	}
+
+	var models []recordModelV0
+	diags.Append(set.ElementsAs(ctx, &models, false)...)
+	if diags.HasError() {
+		return types.SetNull(recordObjectType()), diags
+	}
+	records := make([]*api.DomainRecord, 0, len(models))
+	for _, m := range models {
+		rec := &api.DomainRecord{
+			Name:     m.Name.ValueString(),
+			Type:     m.Type.ValueString(),
+			Data:     m.Data.ValueString(),
+			TTL:      int(m.TTL.ValueInt64()),
+			Priority: int(m.Priority.ValueInt64()),
+			Weight:   int(m.Weight.ValueInt64()),
+			Service:  m.Service.ValueString(),
+			Protocol: m.Protocol.ValueString(),
+		}
+		if port := int(m.Port.ValueInt64()); port != 0 {
+			rec.Port = &port
+		}
+		records = append(records, rec)
+	}
+	return recordsToSet(records)
+}